@@ -2,70 +2,163 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"math/big"
 
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/urfave/cli/v2"
 
-	"github.com/wakamex/rysk-v12-cli/ryskcore" // Added for ryskcore components
+	"github.com/wakamex/rysk-v12-cli/ryskcore"
 )
 
+// allowanceFlags select the token and spender of an approval from the chain registry.
+var allowanceFlags = []cli.Flag{
+	&cli.Int64Flag{
+		Name:     "chain_id",
+		Required: true,
+		Usage:    "chain_id",
+	},
+	&cli.StringFlag{
+		Name:  "asset",
+		Value: "strike",
+		Usage: "token to approve: \"strike\", a symbol from the chain's asset catalogue, or a token address",
+	},
+	&cli.StringFlag{
+		Name:  "spender",
+		Value: "MMarket",
+		Usage: "spender to approve: MMarket, MarginPool, or the address of either",
+	},
+}
+
 var approveAction = &cli.Command{
 	Name:  "approve",
-	Usage: "approve spending of default strike asset",
-	Flags: []cli.Flag{
-		&cli.Int64Flag{
-			Name:     "chain_id",
-			Required: true,
-			Usage:    "chain_id",
-		},
+	Usage: "approve spending of the strike asset, or another asset, by a protocol contract",
+	Flags: joinFlags(allowanceFlags, rpcFlags, []cli.Flag{
 		&cli.StringFlag{
-			Name:     "rpc_url",
-			Required: true,
-			Usage:    "rpc url",
+			Name:  "amount",
+			Usage: "amount to approve, required unless --ensure or --max is set",
 		},
-		&cli.StringFlag{
-			Name:     "amount",
-			Required: true,
-			Usage:    "amount to approve",
+		&cli.BoolFlag{
+			Name:  "max",
+			Usage: "approve the maximum uint256 amount",
 		},
 		&cli.StringFlag{
-			Name:     "private_key",
-			Required: true,
-			Usage:    "private key of approving account",
+			Name:  "ensure",
+			Usage: "only approve if the current allowance is below this amount; approves --amount, --max or this amount",
 		},
-	},
+		unitsFlag,
+	}, privateKeyFlags, txFlags),
 	Action: func(c *cli.Context) error {
 		return approveCmdFunc(c)
 	},
 }
 
+var revokeAction = &cli.Command{
+	Name:  "revoke",
+	Usage: "set the allowance of a spender to zero",
+	Flags: joinFlags(allowanceFlags, rpcFlags, privateKeyFlags, txFlags),
+	Action: func(c *cli.Context) error {
+		return revokeCmdFunc(c)
+	},
+}
+
 func approveCmdFunc(c *cli.Context) error {
 	chain_id := c.Int("chain_id")
-	rpc_url := c.String("rpc_url")
-	amount := c.String("amount")
-	pk := c.String("private_key")
 
-	account, err := ryskcore.NewAccountFromPrivateKey(pk)
+	switch {
+	case c.Bool("max") && c.IsSet("amount"):
+		return fmt.Errorf("--amount and --max are mutually exclusive")
+	case !c.Bool("max") && !c.IsSet("amount") && !c.IsSet("ensure"):
+		return fmt.Errorf("one of --amount, --max or --ensure is required")
+	}
+
+	token, spender, err := allowanceFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	account, err := accountFromContext(c)
+	if err != nil {
+		return err
+	}
+	defer account.Clear()
+
+	txOpts, err := txOptionsFromContext(c)
 	if err != nil {
 		return err
 	}
 
-	bigAmount, ok := new(big.Int).SetString(amount, 10)
-	if !ok {
-		return fmt.Errorf("%s cannot be turned into a big.Int", amount)
+	pool, err := dialFromContext(c, chain_id)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	client := pool.Client()
+
+	decimals := tokenDecimals(c, client, token)
+	var min, amount *big.Int
+	if c.IsSet("ensure") {
+		if min, err = parseAmount(c, "ensure", decimals); err != nil {
+			return err
+		}
+		amount = min
+	}
+	switch {
+	case c.Bool("max"):
+		amount = math.MaxBig256
+	case c.IsSet("amount"):
+		if amount, err = parseAmount(c, "amount", decimals); err != nil {
+			return err
+		}
+	}
+
+	if min == nil {
+		return printTxResult(account.ApproveSpender(c.Context, chain_id, client, token, spender, amount, txOpts))
+	}
+	result, err := account.EnsureAllowance(c.Context, chain_id, client, token, spender, min, amount, txOpts)
+	if result == nil && err == nil {
+		log.Printf("Allowance of %s already covers %s, nothing sent", spender.Hex(), min)
+	}
+	return printTxResult(result, err)
+}
+
+func revokeCmdFunc(c *cli.Context) error {
+	token, spender, err := allowanceFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	account, err := accountFromContext(c)
+	if err != nil {
+		return err
 	}
+	defer account.Clear()
 
-	client, err := ethclient.DialContext(c.Context, rpc_url)
+	txOpts, err := txOptionsFromContext(c)
 	if err != nil {
 		return err
 	}
 
-	txHash, err := account.Approve(c.Context, int(chain_id), *client, bigAmount) // Cast chain_id to int for Approve method
+	pool, err := dialFromContext(c, c.Int("chain_id"))
 	if err != nil {
 		return err
 	}
+	defer pool.Close()
+	client := pool.Client()
 
-	fmt.Println(txHash)
-	return nil
+	return printTxResult(account.ApproveSpender(c.Context, c.Int("chain_id"), client, token, spender, new(big.Int), txOpts))
+}
+
+// allowanceFromContext resolves --asset and --spender against the chain registry.
+func allowanceFromContext(c *cli.Context) (token, spender common.Address, err error) {
+	chain, err := ryskcore.GetChain(c.Int("chain_id"))
+	if err != nil {
+		return token, spender, err
+	}
+	if token, err = chain.Asset(c.String("asset")); err != nil {
+		return token, spender, err
+	}
+	spender, err = chain.Spender(c.String("spender"))
+	return token, spender, err
 }
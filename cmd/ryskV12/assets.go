@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/goccy/go-json"
+	"github.com/urfave/cli/v2"
+
+	"github.com/wakamex/rysk-v12-cli/ryskcore"
+)
+
+var assetsAction = &cli.Command{
+	Name:  "assets",
+	Usage: "list the asset catalogue of a chain: the symbols --asset accepts",
+	Flags: []cli.Flag{
+		&cli.Int64Flag{
+			Name:     "chain_id",
+			Required: true,
+			Usage:    "chain_id",
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "print the catalogue as JSON",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		return assetsCmdFunc(c)
+	},
+}
+
+func assetsCmdFunc(c *cli.Context) error {
+	chain, err := ryskcore.GetChain(int(c.Int64("chain_id")))
+	if err != nil {
+		return err
+	}
+	assets := append([]ryskcore.AssetInfo{}, chain.Assets...)
+	sort.SliceStable(assets, func(i, j int) bool {
+		return strings.ToLower(assets[i].Symbol) < strings.ToLower(assets[j].Symbol)
+	})
+
+	if c.Bool("json") {
+		out, err := json.MarshalIndent(assets, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SYMBOL\tADDRESS\tDECIMALS\tROLE")
+	for _, a := range assets {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", a.Symbol, a.Address.Hex(), a.Decimals, a.Role)
+	}
+	return w.Flush()
+}
+
+// assetFromContext returns --asset as given if it is an address, or else the
+// address of the asset it names on chainID, such as WETH or strike.
+func assetFromContext(c *cli.Context, chainID int) (string, error) {
+	asset := c.String("asset")
+	if common.IsHexAddress(asset) {
+		return asset, nil
+	}
+	address, err := ryskcore.ResolveAsset(chainID, asset)
+	if err != nil {
+		return "", fmt.Errorf("--asset: %w", err)
+	}
+	return address.Hex(), nil
+}
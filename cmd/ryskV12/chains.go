@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/goccy/go-json"
+	"github.com/urfave/cli/v2"
+
+	"github.com/wakamex/rysk-v12-cli/ryskcore"
+)
+
+// chainsConfigFlag is a global flag, loaded before any command runs.
+var chainsConfigFlag = &cli.StringFlag{
+	Name:    "chains_config",
+	EnvVars: []string{"RYSK_CHAINS_CONFIG"},
+	Usage:   "JSON or YAML file with chains to add to or override in the built-in registry",
+}
+
+func loadChainsConfig(c *cli.Context) error {
+	if path := c.String("chains_config"); path != "" {
+		return ryskcore.LoadChains(path)
+	}
+	return nil
+}
+
+var chainsAction = &cli.Command{
+	Name:  "chains",
+	Usage: "list the configured chains and protocol addresses",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "print the registry as JSON",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		return chainsCmdFunc(c)
+	},
+}
+
+func chainsCmdFunc(c *cli.Context) error {
+	var chains []ryskcore.Chain
+	for _, id := range ryskcore.ChainIDs() {
+		chain, _ := ryskcore.GetChain(id)
+		chains = append(chains, chain)
+	}
+
+	if c.Bool("json") {
+		out, err := json.MarshalIndent(chains, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHAIN ID\tNAME\tSTRIKE ASSET\tMMARKET\tMARGIN POOL\tRPC")
+	for _, chain := range chains {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", chain.ChainID, chain.Name,
+			chain.StrikeAsset.Hex(), chain.MMarket.Hex(), chain.MarginPool.Hex(), strings.Join(chain.RPCURLs, ","))
+	}
+	return w.Flush()
+}
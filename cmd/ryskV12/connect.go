@@ -1,13 +1,13 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"log"
 	"net"
 	"os"
 	"strings"
+	"sync"
 	"time" // Added to resolve undefined: time errors
 
 	"github.com/goccy/go-json"
@@ -39,6 +39,15 @@ var connectAction = &cli.Command{
 			Usage:    "WebSocket URL to connect to (e.g., wss://api.rysk.finance/ws)",
 		},
 		// --role flag and X-Rysk-Role header functionality removed as per user request.
+		&cli.StringFlag{
+			Name:  "requote_hook",
+			Usage: "shell command to run when another maker's quote becomes the best on an rfq we quoted",
+		},
+		&cli.DurationFlag{
+			Name:  "requote_timeout",
+			Value: 5 * time.Second,
+			Usage: "kill the requote hook after this long",
+		},
 	},
 	Action: func(c *cli.Context) error {
 		return connectCmdFunc(c) // Renamed to avoid conflict
@@ -73,16 +82,29 @@ func connectCmdFunc(c *cli.Context) error {
 	}
 	log.Printf("Successfully connected to WebSocket: %s", c.String("url"))
 
+	// Quote notifications update the quote book and are published to IPC subscribers.
+	hub := newIPCHub()
+	book := ryskcore.NewQuoteBook()
+	if command := c.String("requote_hook"); command != "" {
+		hook := newRequoteHook(command, c.Duration("requote_timeout"), channelID)
+		book.OnOutbid = func(status ryskcore.QuoteStatus) {
+			hook.run(c.Context, status)
+		}
+	}
+
 	// Set a handler for messages received from the WebSocket via ryskcore.Client
 	ryskClient.SetHandler(func(msg []byte) {
 		// Process or display messages from the WebSocket
 		// For example, log them or forward to connected IPC clients if needed.
 		fmt.Printf("Received from WebSocket: %s\n", string(msg))
+		if n, err := ryskcore.ParseQuoteNotification(msg); err == nil {
+			hub.publish(topicQuotes, book.Update(n))
+		}
 	})
 
 	// Start goroutine to accept commands from the Unix domain socket
 	// Use c.Context for this goroutine as well, so it stops when the command context is done.
-	go pipeCommands(c.Context, ln, cmdChan)
+	go pipeCommands(c.Context, ln, cmdChan, hub)
 
 	log.Println("Connect command running. Waiting for IPC commands or context cancellation.")
 
@@ -120,12 +142,26 @@ func connectCmdFunc(c *cli.Context) error {
 				return nil // Or call a cancel func if connectCmdFunc managed its own cancellable context
 			} else {
 				log.Printf("Relaying IPC command to WebSocket: %s", string(cmd))
+				trackQuote(book, cmd)
 				ryskClient.Send(cmd)
 			}
 		}
 	}
 }
 
+// trackQuote records cmd in book if it is a quote, so that notifications on its rfq
+// can be compared with it.
+func trackQuote(book *ryskcore.QuoteBook, cmd []byte) {
+	var req struct {
+		ID     string         `json:"id"`
+		Method string         `json:"method"`
+		Params ryskcore.Quote `json:"params"`
+	}
+	if json.Unmarshal(cmd, &req) == nil && req.Method == "quote" {
+		book.Quoted(req.ID, req.Params)
+	}
+}
+
 // writeToSocket is used by other CLI commands (quote, transfer) to send data to the connect command's Unix socket.
 func writeToSocket(channelID string, payload any) error {
 	data, err := json.Marshal(payload)
@@ -148,19 +184,32 @@ func writeToSocket(channelID string, payload any) error {
 	return nil
 }
 
-// pipeCommands accepts connections on the Unix domain socket and forwards commands.
-func pipeCommands(ctx context.Context, ln *net.UnixListener, cmdChan chan<- []byte) {
-	defer close(cmdChan) // Close cmdChan when pipeCommands exits
+// pipeCommands accepts connections on the Unix domain socket and serves each of them
+// concurrently, so that subscribers do not block commands.
+func pipeCommands(ctx context.Context, ln *net.UnixListener, cmdChan chan<- []byte, hub *ipcHub) {
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		conns = make(map[*net.UnixConn]struct{})
+	)
+	defer func() {
+		// Unblock the connections still being read before closing cmdChan.
+		mu.Lock()
+		for conn := range conns {
+			conn.Close()
+		}
+		mu.Unlock()
+		wg.Wait()
+		close(cmdChan)
+	}()
 	for {
-		var unixConn *net.UnixConn
-		var err error
 		// Set a deadline for Accept so it doesn't block indefinitely and can check ctx.Done()
 		if err := ln.SetDeadline(time.Now().Add(500 * time.Millisecond)); err != nil {
 			log.Printf("pipeCommands: failed to set listener deadline: %v", err)
 			return // or handle error more gracefully
 		}
 
-		unixConn, err = ln.AcceptUnix()
+		unixConn, err := ln.AcceptUnix()
 		if err != nil {
 			if ne, ok := err.(net.Error); ok && ne.Timeout() {
 				select {
@@ -175,37 +224,17 @@ func pipeCommands(ctx context.Context, ln *net.UnixListener, cmdChan chan<- []by
 			return // Exit if a non-timeout error occurs or if context is not done yet (unexpected)
 		}
 
-		// Handle each connection in a new goroutine to allow multiple simultaneous IPC clients (optional)
-		// For simplicity here, handling one at a time. For concurrent, wrap the below in go func() { ... }()
 		log.Printf("IPC connection accepted from: %s", unixConn.RemoteAddr())
-		scanner := bufio.NewScanner(unixConn)
-		for scanner.Scan() {
-			cmdBytes := scanner.Bytes()
-			// It's important to copy the bytes if they are to be used beyond this iteration
-			// as scanner.Bytes() may reuse the buffer.
-			cmdCopy := make([]byte, len(cmdBytes))
-			copy(cmdCopy, cmdBytes)
-
-			select {
-			case cmdChan <- cmdCopy:
-			case <-ctx.Done():
-				log.Println("pipeCommands: context done while sending to cmdChan.")
-				unixConn.Close()
-				return
-			}
-		}
-		if err := scanner.Err(); err != nil {
-			log.Printf("pipeCommands: error reading from IPC socket: %v", err)
-		}
-		unixConn.Close()
-		log.Println("IPC connection closed.")
-		// Check context again before looping to accept a new connection
-		select {
-		case <-ctx.Done():
-			log.Println("pipeCommands: context done after handling a connection.")
-			return
-		default:
-			// continue to next accept
-		}
+		mu.Lock()
+		conns[unixConn] = struct{}{}
+		mu.Unlock()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serveIPC(ctx, unixConn, cmdChan, hub)
+			mu.Lock()
+			delete(conns, unixConn)
+			mu.Unlock()
+		}()
 	}
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/wakamex/rysk-v12-cli/ryskcore"
+)
+
+// privateKeyFlags are the ways a command can be given its private key.
+// At most one of them may be set.
+var privateKeyFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "private_key",
+		Usage: "private key, hex with or without 0x",
+	},
+	&cli.StringFlag{
+		Name:  "private_key_file",
+		Usage: "read the private key from a file",
+	},
+	&cli.StringFlag{
+		Name:  "private_key_env",
+		Usage: "read the private key from this environment variable",
+	},
+	&cli.BoolFlag{
+		Name:  "private_key_stdin",
+		Usage: "read the private key from the first line of stdin",
+	},
+	&cli.StringFlag{
+		Name:  "private_key_cmd",
+		Usage: "run this shell command and read the private key from its stdout, e.g. 'pass show rysk/maker'",
+	},
+}
+
+// keySourceFromContext returns the key source selected by privateKeyFlags, or nil if
+// none is set.
+func keySourceFromContext(c *cli.Context) (ryskcore.KeySource, error) {
+	var sources []ryskcore.KeySource
+	if c.IsSet("private_key") {
+		sources = append(sources, ryskcore.KeyFromString(c.String("private_key")))
+	}
+	if c.IsSet("private_key_file") {
+		sources = append(sources, ryskcore.KeyFromFile(c.String("private_key_file")))
+	}
+	if c.IsSet("private_key_env") {
+		sources = append(sources, ryskcore.KeyFromEnv(c.String("private_key_env")))
+	}
+	if c.Bool("private_key_stdin") {
+		sources = append(sources, ryskcore.KeyFromReader(os.Stdin))
+	}
+	if c.IsSet("private_key_cmd") {
+		sources = append(sources, ryskcore.KeyFromCommand(c.Context, c.String("private_key_cmd")))
+	}
+
+	switch len(sources) {
+	case 0:
+		return nil, nil
+	case 1:
+		return sources[0], nil
+	default:
+		return nil, fmt.Errorf("only one of --private_key, --private_key_file, --private_key_env, --private_key_stdin or --private_key_cmd may be set")
+	}
+}
+
+// accountFromContext loads the account selected by privateKeyFlags.
+// Callers should defer account.Clear().
+func accountFromContext(c *cli.Context) (ryskcore.Account, error) {
+	src, err := keySourceFromContext(c)
+	if err != nil {
+		return ryskcore.Account{}, err
+	}
+	if src == nil {
+		return ryskcore.Account{}, fmt.Errorf("a private key is required, see --private_key and related flags")
+	}
+	return ryskcore.NewAccountFromKeySource(src)
+}
@@ -11,22 +11,37 @@ func main() {
 	app := &cli.App{
 		Name:  "ryskV12",
 		Usage: "CLI for Rysk V1.2 System",
+		Flags: []cli.Flag{
+			chainsConfigFlag,
+			stateDirFlag,
+		},
+		Before: loadChainsConfig,
 		Commands: []*cli.Command{
 			approveAction, // Refactored and added
+			assetsAction,
 			balancesAction, // Refactored and added
+			chainsAction,
 
 			connectAction, // Defined in connect.go (handles disconnect IPC)
 
 			// diconnect.go and diconnectAction removed as 'connect' handles disconnect IPC.
 
+			permitAction,
 			positionsAction, // Refactored and added
 
-			quoteAction,    // Defined in quote.go
+			quoteAction, // Defined in quote.go
+			revokeAction,
+			subscribeAction,
 			transferAction, // Defined in transfer.go
+			txAction,
+			typedDataAction,
+			walletAction,
+			watchChainAction,
 		},
 	}
 
 	if err := app.Run(os.Args); err != nil {
-		log.Fatal(err)
+		log.Print(err)
+		os.Exit(exitCode(err))
 	}
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+
+	"github.com/wakamex/rysk-v12-cli/ryskcore"
+)
+
+// requiredNonceFlag is the nonce of a message that is signed elsewhere, e.g. by
+// typed-data's external wallet, so it must be chosen up front.
+var requiredNonceFlag = &cli.StringFlag{
+	Name:     "nonce",
+	Required: true,
+	Usage:    "nonce to sign the message with",
+}
+
+// messageNonceFlags are the nonce flags of commands sending a signed message.
+var messageNonceFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "nonce",
+		Usage: "nonce to sign the message with, refused if the account already used it (default: generated with --nonce_strategy)",
+	},
+	&cli.StringFlag{
+		Name:    "nonce_strategy",
+		Value:   "timestamp",
+		EnvVars: []string{"RYSK_NONCE_STRATEGY"},
+		Usage:   "how nonces are generated when --nonce is omitted: counter, timestamp or uuid",
+	},
+}
+
+func messageNoncesFromContext(c *cli.Context) (*ryskcore.MessageNonces, error) {
+	path, err := stateFile(c, "message_nonces.json")
+	if err != nil {
+		return nil, err
+	}
+	return ryskcore.NewMessageNonces(path)
+}
+
+// nextMessageNonce generates a nonce for a message from account with
+// --nonce_strategy and records it, so that it is never handed out or accepted again.
+func nextMessageNonce(c *cli.Context, account common.Address) (string, error) {
+	if c.IsSet("signature") {
+		return "", errors.New("--nonce is required with --signature, as the signature covers it")
+	}
+	nonces, err := messageNoncesFromContext(c)
+	if err != nil {
+		return "", err
+	}
+	return nonces.Next(account, c.String("nonce_strategy"))
+}
+
+// useMessageNonce records the nonce given with --nonce as used by account, or fails
+// if account already used it.
+func useMessageNonce(c *cli.Context, account common.Address, nonce string) error {
+	nonces, err := messageNoncesFromContext(c)
+	if err != nil {
+		return err
+	}
+	return nonces.Use(account, nonce)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/goccy/go-json"
+	"github.com/urfave/cli/v2"
+
+	"github.com/wakamex/rysk-v12-cli/ryskcore"
+)
+
+var permitAction = &cli.Command{
+	Name:  "permit",
+	Usage: "sign an EIP-2612 permit for the default strike asset, as a gasless alternative to approve",
+	Flags: joinFlags([]cli.Flag{
+		&cli.Int64Flag{
+			Name:     "chain_id",
+			Required: true,
+			Usage:    "chain_id",
+		},
+		&cli.StringFlag{
+			Name:     "amount",
+			Required: true,
+			Usage:    "amount to permit",
+		},
+		&cli.Int64Flag{
+			Name:  "deadline",
+			Usage: "unix timestamp after which the permit is invalid (default: one hour from now)",
+		},
+		&cli.StringFlag{
+			Name:  "token",
+			Usage: "token to permit (default: strike asset of the chain)",
+		},
+		&cli.StringFlag{
+			Name:  "spender",
+			Usage: "spender to permit (default: MMarket of the chain)",
+		},
+		&cli.StringFlag{
+			Name:  "token_version",
+			Usage: "EIP-712 version of the token (default: read from the token)",
+		},
+		&cli.BoolFlag{
+			Name:  "submit",
+			Usage: "also send the permit on-chain",
+		},
+		unitsFlag,
+	}, rpcFlags, privateKeyFlags, txFlags),
+	Action: func(c *cli.Context) error {
+		return permitCmdFunc(c)
+	},
+}
+
+func permitCmdFunc(c *cli.Context) error {
+	chainID := int(c.Int64("chain_id"))
+
+	account, err := accountFromContext(c)
+	if err != nil {
+		return err
+	}
+	defer account.Clear()
+
+	deadline := big.NewInt(c.Int64("deadline"))
+	if !c.IsSet("deadline") {
+		deadline = big.NewInt(time.Now().Add(time.Hour).Unix())
+	}
+
+	token := common.HexToAddress(c.String("token"))
+	spender := common.HexToAddress(c.String("spender"))
+	if !c.IsSet("token") || !c.IsSet("spender") {
+		addresses, err := ryskcore.GetAddresses(chainID)
+		if err != nil {
+			return err
+		}
+		if !c.IsSet("token") {
+			token = addresses.StrikeAsset
+		}
+		if !c.IsSet("spender") {
+			spender = addresses.MMarket
+		}
+	}
+
+	pool, err := dialFromContext(c, chainID)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	client := pool.Client()
+
+	value, err := parseAmount(c, "amount", tokenDecimals(c, client, token))
+	if err != nil {
+		return err
+	}
+
+	permit, err := account.SignPermit(c.Context, chainID, client, token, spender, value, deadline, c.String("token_version"))
+	if err != nil {
+		return err
+	}
+	out, err := json.Marshal(permit)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+
+	if c.Bool("submit") {
+		txOpts, err := txOptionsFromContext(c)
+		if err != nil {
+			return err
+		}
+		return printTxResult(account.SubmitPermit(c.Context, chainID, client, permit, txOpts))
+	}
+	return nil
+}
@@ -1,26 +1,64 @@
 package main
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/urfave/cli/v2"
 	"github.com/wakamex/rysk-v12-cli/ryskcore" // Adjust if your fork's module path is different
 )
 
-// JsonRPCRequest needs to be defined in this package or imported if it's a shared type for the CLI.
-// For example:
-// type JsonRPCRequest struct {
-// 	JsonRPC string      `json:"jsonrpc"`
-// 	ID      string      `json:"id"`
-// 	Method  string      `json:"method"`
-// 	Params  interface{} `json:"params,omitempty"`
-// }
-// 
-// writeToSocket also needs to be defined or imported for this package.
-// func writeToSocket(channelID string, payload interface{}) error { /* ... */ return nil }
+// quoteMessageFlags are the flags describing the signed fields of a quote.
+// They are shared by `quote` and `typed-data quote`.
+var quoteMessageFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:     "asset",
+		Required: true,
+		Usage:    "asset address, or symbol from the chain's asset catalogue (see assets)",
+	},
+	&cli.IntFlag{
+		Name:     "chain_id",
+		Required: true,
+	},
+	&cli.Int64Flag{
+		Name:     "expiry",
+		Required: true,
+	},
+	&cli.BoolFlag{
+		Name: "is_put",
+	},
+	&cli.BoolFlag{
+		Name: "is_taker_buy",
+	},
+	&cli.StringFlag{
+		Name:     "maker",
+		Required: true,
+	},
+	&cli.StringFlag{
+		Name:     "price",
+		Required: true,
+	},
+	&cli.StringFlag{
+		Name:     "quantity",
+		Required: true,
+	},
+	&cli.StringFlag{
+		Name:     "strike",
+		Required: true,
+	},
+	&cli.StringFlag{
+		Name:     "valid_until", // Corrected from "valid_untill"
+		Required: true,
+	},
+	unitsFlag,
+}
 
 var quoteAction = &cli.Command{
 	Name:  "quote",
 	Usage: "Send a quote",
-	Flags: []cli.Flag{
+	Flags: joinFlags([]cli.Flag{
 		&cli.StringFlag{
 			Name:     "channel_id",
 			Required: true,
@@ -31,97 +69,105 @@ var quoteAction = &cli.Command{
 			Required: true,
 			Usage:    "the rfq id to respond to",
 		},
-		&cli.StringFlag{
-			Name:     "asset",
-			Required: true,
-			Usage:    "asset address",
-		},
-		&cli.IntFlag{
-			Name:     "chain_id",
-			Required: true,
-		},
-		&cli.Int64Flag{
-			Name:     "expiry",
-			Required: true,
-		},
-		&cli.BoolFlag{
-			Name: "is_put",
-		},
 		&cli.BoolFlag{
-			Name: "is_taker_buy",
-		},
-		&cli.StringFlag{
-			Name:     "maker",
-			Required: true,
+			Name:  "skip_validation",
+			Usage: "sign and send the quote without checking it first",
 		},
-		&cli.StringFlag{
-			Name:     "nonce",
-			Required: true,
-		},
-		&cli.StringFlag{
-			Name:     "price",
-			Required: true,
-		},
-		&cli.StringFlag{
-			Name:     "quantity",
-			Required: true,
-		},
-		&cli.StringFlag{
-			Name:     "strike",
-			Required: true,
-		},
-		&cli.StringFlag{
-			Name:     "valid_until", // Corrected from "valid_untill"
-			Required: true,
-		},
-		&cli.StringFlag{
-			Name:     "private_key",
-			Required: true,
-			Usage:    "private key to sign messages with",
-		},
-	},
+	}, quoteMessageFlags, messageNonceFlags, domainFlags, signingFlags),
 	Action: func(c *cli.Context) error {
 		return quoteCmdFunc(c) // Renamed to avoid conflict if quote were a type
 	},
 }
 
+// quoteFromContext builds an unsigned quote from the quoteMessageFlags. The asset may
+// be given by symbol. Price, quantity and strike are converted to base units as set
+// by --units.
+func quoteFromContext(c *cli.Context) (ryskcore.Quote, error) {
+	q := ryskcore.Quote{
+		ChainID:    c.Int("chain_id"),
+		Expiry:     c.Int64("expiry"),
+		IsPut:      c.Bool("is_put"),
+		IsTakerBuy: c.Bool("is_taker_buy"),
+		Maker:      c.String("maker"),
+		Nonce:      c.String("nonce"),
+		ValidUntil: c.Int64("valid_until"),
+	}
+	var err error
+	if q.AssetAddress, err = assetFromContext(c, q.ChainID); err != nil {
+		return q, err
+	}
+	if q.Price, err = quoteAmount(c, "price", ryskcore.ParsePrice); err != nil {
+		return q, err
+	}
+	if q.Quantity, err = quoteAmount(c, "quantity", ryskcore.ParseQuantity); err != nil {
+		return q, err
+	}
+	q.Strike, err = quoteAmount(c, "strike", ryskcore.ParseStrike)
+	return q, err
+}
+
 func quoteCmdFunc(c *cli.Context) error {
 	channelID := c.String("channel_id")
 	rfqID := c.String("rfq_id") // Corrected variable name to rfqID for consistency
-	pk := c.String("private_key")
 
-	// Assuming JsonRPCRequest is defined in this 'main' package scope or imported.
 	payload := JsonRPCRequest{
 		JsonRPC: "2.0",
 		ID:      rfqID,
 		Method:  "quote",
 	}
 
-	q := ryskcore.Quote{
-		AssetAddress: c.String("asset"),
-		ChainID:      c.Int("chain_id"),
-		Expiry:       c.Int64("expiry"),
-		IsPut:        c.Bool("is_put"),
-		IsTakerBuy:   c.Bool("is_taker_buy"),
-		Maker:        c.String("maker"),
-		Nonce:        c.String("nonce"),
-		Price:        c.String("price"),
-		Quantity:     c.String("quantity"),
-		Strike:       c.String("strike"),
-		ValidUntil:   c.Int64("valid_until"),
+	q, err := quoteFromContext(c)
+	if err != nil {
+		return err
+	}
+	if err := applyDomainFlags(c, q.ChainID, "Quote"); err != nil {
+		return err
+	}
+	// Nonces are tracked per maker, whoever signs. A nonce given with --nonce is
+	// checked and recorded once the quote is validated, so that a quote failing
+	// validation can be fixed and resent, but before it is signed.
+	if !common.IsHexAddress(q.Maker) {
+		return fmt.Errorf("invalid maker address %q", q.Maker)
+	}
+	maker := common.HexToAddress(q.Maker)
+	generated := q.Nonce == ""
+	if generated {
+		if q.Nonce, err = nextMessageNonce(c, maker); err != nil {
+			return err
+		}
+	}
+	validate := !c.Bool("skip_validation")
+	if validate {
+		if err := q.Validate(time.Now()); err != nil {
+			return fmt.Errorf("%w (use --skip_validation to send it anyway)", err)
+		}
+	}
+	if !generated {
+		if err := useMessageNonce(c, maker, q.Nonce); err != nil {
+			return err
+		}
 	}
 
-	msgHash, _, err := ryskcore.CreateQuoteMessage(q)
+	msgHash, err := ryskcore.QuoteHash(q)
 	if err != nil {
 		return err
 	}
-	sig, err := ryskcore.Sign(msgHash, pk)
+	s, err := signerFromContext(c)
 	if err != nil {
 		return err
 	}
+	defer s.clear()
+	sig, signer, err := s.sign(msgHash.Bytes())
+	if err != nil {
+		return err
+	}
+	// The signature must come from the maker, otherwise the server rejects the quote.
+	// This is always checked for imported signatures.
+	if (validate || c.IsSet("signature")) && !strings.EqualFold(signer.Hex(), q.Maker) {
+		return fmt.Errorf("signature is from %s, not maker %s", signer.Hex(), q.Maker)
+	}
 	q.Signature = sig
 	payload.Params = q
 
-	// Assuming writeToSocket is defined in this 'main' package scope or imported.
 	return writeToSocket(channelID, payload)
 }
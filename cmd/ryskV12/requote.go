@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"github.com/wakamex/rysk-v12-cli/ryskcore"
+)
+
+// requoteHook runs a shell command when we are outbid on an RFQ, so that a strategy
+// can send an improved quote. The command gets the ryskcore.QuoteStatus as JSON on
+// stdin and its main fields in the environment:
+//
+//	RYSK_CHANNEL_ID      the channel to send the new quote into
+//	RYSK_RFQ_ID          the RFQ we were outbid on
+//	RYSK_NEW_BEST        the best price on the RFQ
+//	RYSK_PREVIOUS_PRICE  our price, as notified or as last quoted
+//	RYSK_VALID_UNTIL     when our last quote expires, if it went through this channel
+//
+// At most one command runs per RFQ; notifications arriving meanwhile are skipped.
+type requoteHook struct {
+	command   string
+	timeout   time.Duration
+	channelID string
+
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+func newRequoteHook(command string, timeout time.Duration, channelID string) *requoteHook {
+	return &requoteHook{command: command, timeout: timeout, channelID: channelID, running: make(map[string]bool)}
+}
+
+// run starts the command for status in the background, unless one is already
+// running for its RFQ.
+func (h *requoteHook) run(ctx context.Context, status ryskcore.QuoteStatus) {
+	h.mu.Lock()
+	if h.running[status.RFQID] {
+		h.mu.Unlock()
+		log.Printf("Requote hook still running for rfq %s, skipping new best %s", status.RFQID, status.Best)
+		return
+	}
+	h.running[status.RFQID] = true
+	h.mu.Unlock()
+
+	go func() {
+		defer func() {
+			h.mu.Lock()
+			delete(h.running, status.RFQID)
+			h.mu.Unlock()
+		}()
+		if err := h.exec(ctx, status); err != nil {
+			log.Printf("Requote hook for rfq %s failed: %v", status.RFQID, err)
+		}
+	}()
+}
+
+func (h *requoteHook) exec(ctx context.Context, status ryskcore.QuoteStatus) error {
+	input, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	previous := status.Yours
+	var validUntil string
+	if status.Quote != nil {
+		if previous == "" {
+			previous = status.Quote.Price.String()
+		}
+		validUntil = strconv.FormatInt(status.Quote.ValidUntil, 10)
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.command)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(os.Environ(),
+		"RYSK_CHANNEL_ID="+h.channelID,
+		"RYSK_RFQ_ID="+status.RFQID,
+		"RYSK_NEW_BEST="+status.Best,
+		"RYSK_PREVIOUS_PRICE="+previous,
+		"RYSK_VALID_UNTIL="+validUntil,
+	)
+	out, err := cmd.CombinedOutput()
+	if len(out) > 0 {
+		log.Printf("Requote hook for rfq %s: %s", status.RFQID, bytes.TrimSpace(out))
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/urfave/cli/v2"
+
+	"github.com/wakamex/rysk-v12-cli/ryskcore"
+)
+
+// rpcFlags select the RPC endpoints of commands that talk to the chain.
+var rpcFlags = []cli.Flag{
+	&cli.StringSliceFlag{
+		Name:  "rpc_url",
+		Usage: "rpc url, repeat or comma separate for failover (default: the chain's rpc urls from the registry)",
+	},
+	&cli.Uint64Flag{
+		Name:  "max_head_lag",
+		Value: ryskcore.DefaultMaxHeadLag,
+		Usage: "skip rpc endpoints more than this many blocks behind the best one",
+	},
+}
+
+// dialFromContext connects to the --rpc_url endpoints, or the registry's endpoints of
+// chainID, and checks that they serve chainID. Callers must Close the pool.
+func dialFromContext(c *cli.Context, chainID int) (*ryskcore.RPCPool, error) {
+	urls := c.StringSlice("rpc_url")
+	if len(urls) == 0 {
+		chain, err := ryskcore.GetChain(chainID)
+		if err != nil {
+			return nil, err
+		}
+		urls = chain.RPCURLs
+	}
+	return ryskcore.DialRPCPool(c.Context, chainID, urls, ryskcore.RPCOptions{
+		MaxHeadLag: c.Uint64("max_head_lag"),
+	})
+}
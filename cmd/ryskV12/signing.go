@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+
+	"github.com/wakamex/rysk-v12-cli/ryskcore"
+)
+
+// signingFlags select how a message gets its signature: signed locally with one of
+// the privateKeyFlags, or imported from an external wallet with --signature.
+var signingFlags = joinFlags(privateKeyFlags, []cli.Flag{
+	&cli.StringFlag{
+		Name:  "signature",
+		Usage: "signature produced by an external wallet (see typed-data), used instead of a private key",
+	},
+})
+
+// domainFlags override the EIP-712 domain a command signs its message with.
+var domainFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "domain_config",
+		Usage: "JSON file with per chain and per message type EIP-712 domains",
+	},
+	&cli.StringFlag{
+		Name:  "domain_name",
+		Usage: "override the EIP-712 domain name",
+	},
+	&cli.StringFlag{
+		Name:  "domain_version",
+		Usage: "override the EIP-712 domain version",
+	},
+	&cli.StringFlag{
+		Name:  "domain_verifying_contract",
+		Usage: "override the EIP-712 domain verifying contract",
+	},
+	&cli.StringFlag{
+		Name:  "domain_salt",
+		Usage: "set the EIP-712 domain salt (32 bytes hex)",
+	},
+}
+
+// applyDomainFlags loads --domain_config, then applies the individual domain
+// flags to primaryType messages on chainID.
+func applyDomainFlags(c *cli.Context, chainID int, primaryType string) error {
+	if path := c.String("domain_config"); path != "" {
+		if err := ryskcore.LoadDomains(path); err != nil {
+			return err
+		}
+	}
+	return ryskcore.SetDomain(chainID, primaryType, ryskcore.Domain{
+		Name:              c.String("domain_name"),
+		Version:           c.String("domain_version"),
+		VerifyingContract: c.String("domain_verifying_contract"),
+		Salt:              c.String("domain_salt"),
+	})
+}
+
+// joinFlags concatenates flag groups into a new slice.
+func joinFlags(groups ...[]cli.Flag) []cli.Flag {
+	var flags []cli.Flag
+	for _, g := range groups {
+		flags = append(flags, g...)
+	}
+	return flags
+}
+
+// messageSigner signs messages with a private key, or passes on a signature
+// imported with --signature.
+type messageSigner struct {
+	account  *ryskcore.Account
+	imported string
+}
+
+// signerFromContext loads the private key, or takes --signature. Exactly one of them
+// must be given. Callers should defer clear().
+func signerFromContext(c *cli.Context) (*messageSigner, error) {
+	src, err := keySourceFromContext(c)
+	if err != nil {
+		return nil, err
+	}
+	sig := c.String("signature")
+
+	switch {
+	case src != nil && sig != "":
+		return nil, fmt.Errorf("a private key and --signature are mutually exclusive")
+	case src != nil:
+		account, err := ryskcore.NewAccountFromKeySource(src)
+		if err != nil {
+			return nil, err
+		}
+		return &messageSigner{account: &account}, nil
+	case sig == "":
+		return nil, fmt.Errorf("a private key or --signature is required")
+	}
+	return &messageSigner{imported: sig}, nil
+}
+
+// address returns the address of the private key, if signing with one. The signer
+// of an imported signature is only known once the message hash is.
+func (s *messageSigner) address() (common.Address, bool) {
+	if s.account == nil {
+		return common.Address{}, false
+	}
+	return s.account.Public, true
+}
+
+// sign returns a signature for msgHash along with the address that produced it.
+// Imported signatures are normalized to the form of local ones, so the server gets
+// the same format whichever way a message was signed.
+func (s *messageSigner) sign(msgHash []byte) (string, common.Address, error) {
+	var sig string
+	var err error
+	if s.account != nil {
+		sig, err = s.account.Sign(msgHash)
+	} else {
+		sig, err = ryskcore.NormalizeSignature(s.imported)
+	}
+	if err != nil {
+		return "", common.Address{}, err
+	}
+	signer, err := ryskcore.RecoverSigner(msgHash, sig)
+	if err != nil {
+		return "", common.Address{}, err
+	}
+	return sig, signer, nil
+}
+
+func (s *messageSigner) clear() {
+	if s.account != nil {
+		s.account.Clear()
+	}
+}
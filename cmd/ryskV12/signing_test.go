@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli/v2"
+
+	"github.com/wakamex/rysk-v12-cli/ryskcore"
+)
+
+// newTestContext returns a context with flags parsed from args, as a command
+// would see them.
+func newTestContext(t *testing.T, flags []cli.Flag, args ...string) *cli.Context {
+	t.Helper()
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	for _, f := range flags {
+		if err := f.Apply(set); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := set.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return cli.NewContext(cli.NewApp(), set, nil)
+}
+
+func TestImportedSignatureIsNormalized(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := ryskcore.Account{Public: crypto.PubkeyToAddress(key.PublicKey), Private: key}
+	hash := crypto.Keccak256([]byte("message"))
+	local, err := account.Sign(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A wallet returning a recovery id of 0/1.
+	raw := common.FromHex(local)
+	raw[64] -= 27
+
+	c := newTestContext(t, signingFlags, "--signature", hexutil.Encode(raw))
+	s, err := signerFromContext(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.clear()
+	if _, ok := s.address(); ok {
+		t.Error("address() is known for an imported signature")
+	}
+	sig, signer, err := s.sign(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig != local || signer != account.Public {
+		t.Errorf("sign = %s, %s, want %s, %s", sig, signer.Hex(), local, account.Public.Hex())
+	}
+}
+
+func TestSignerFromContextNeedsOneSource(t *testing.T) {
+	key := "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	for name, args := range map[string][]string{
+		"none": nil,
+		"both": {"--private_key", key, "--signature", "0x00"},
+	} {
+		if _, err := signerFromContext(newTestContext(t, signingFlags, args...)); err == nil {
+			t.Errorf("%s: signerFromContext succeeded", name)
+		}
+	}
+	s, err := signerFromContext(newTestContext(t, signingFlags, "--private_key", key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.clear()
+	if address, ok := s.address(); !ok || address.Hex() != "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23" {
+		t.Errorf("address() = %s, %v", address.Hex(), ok)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v2"
+)
+
+// stateDirFlag is a global flag for where local state, such as the nonces handed
+// out to in-flight transactions, is kept between runs.
+var stateDirFlag = &cli.StringFlag{
+	Name:    "state_dir",
+	EnvVars: []string{"RYSK_STATE_DIR"},
+	Usage:   "directory for local state (default: ~/.ryskV12)",
+}
+
+// stateFile returns the path of a state file inside the state directory.
+func stateFile(c *cli.Context, name string) (string, error) {
+	dir := c.String("state_dir")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".ryskV12")
+	}
+	return filepath.Join(dir, name), nil
+}
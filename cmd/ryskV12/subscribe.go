@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/urfave/cli/v2"
+)
+
+// topicQuotes streams a ryskcore.QuoteStatus for every quote notification.
+const topicQuotes = "quotes"
+
+var ipcTopics = []string{topicQuotes}
+
+// ipcWriteTimeout bounds writes to subscribers, so a stalled one cannot hold up the
+// connection to the server.
+const ipcWriteTimeout = time.Second
+
+var subscribeAction = &cli.Command{
+	Name:  "subscribe",
+	Usage: "print the messages of a topic published by a running connect command as NDJSON",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "channel_id",
+			Required: true,
+			Usage:    "the socket id of the connect command",
+		},
+		&cli.StringFlag{
+			Name:  "topic",
+			Value: topicQuotes,
+			Usage: "topic to subscribe to: quotes",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		return subscribeCmdFunc(c)
+	},
+}
+
+func subscribeCmdFunc(c *cli.Context) error {
+	socketPath := fmt.Sprintf("/tmp/%s.sock", c.String("channel_id"))
+	conn, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: socketPath, Net: "unix"})
+	if err != nil {
+		return fmt.Errorf("failed to connect to IPC socket %s: %w", socketPath, err)
+	}
+	defer conn.Close()
+	go func() {
+		<-c.Context.Done()
+		conn.Close()
+	}()
+
+	data, err := json.Marshal(JsonRPCRequest{
+		JsonRPC: "2.0",
+		ID:      "subscribe",
+		Method:  "subscribe",
+		Params:  subscribeParams{Topic: c.String("topic")},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to IPC socket %s: %w", socketPath, err)
+	}
+	if _, err := io.Copy(os.Stdout, conn); err != nil && c.Context.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+type subscribeParams struct {
+	Topic string `json:"topic"`
+}
+
+// ipcResponse answers an IPC request handled by the connect command itself.
+type ipcResponse struct {
+	JsonRPC string `json:"jsonrpc"`
+	ID      string `json:"id"`
+	Result  any    `json:"result,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ipcNotification is a message published on a topic; Method is the topic.
+type ipcNotification struct {
+	JsonRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+// ipcHub holds the IPC connections subscribed to each topic.
+type ipcHub struct {
+	mu   sync.Mutex
+	subs map[string]map[*net.UnixConn]struct{}
+}
+
+func newIPCHub() *ipcHub {
+	return &ipcHub{subs: make(map[string]map[*net.UnixConn]struct{})}
+}
+
+// subscribe handles a subscribe request read from conn. It reports whether line was
+// one; other lines are commands to relay.
+func (h *ipcHub) subscribe(conn *net.UnixConn, line []byte) bool {
+	var req struct {
+		ID     string          `json:"id"`
+		Method string          `json:"method"`
+		Params subscribeParams `json:"params"`
+	}
+	if json.Unmarshal(line, &req) != nil || req.Method != "subscribe" {
+		return false
+	}
+
+	resp := ipcResponse{JsonRPC: "2.0", ID: req.ID}
+	topic := req.Params.Topic
+	if !slices.Contains(ipcTopics, topic) {
+		resp.Error = fmt.Sprintf("unknown topic %q, expected one of %v", topic, ipcTopics)
+	} else {
+		resp.Result = "subscribed to " + topic
+	}
+	if err := writeLine(conn, resp); err != nil || resp.Error != "" {
+		return true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[topic] == nil {
+		h.subs[topic] = make(map[*net.UnixConn]struct{})
+	}
+	h.subs[topic][conn] = struct{}{}
+	log.Printf("IPC connection %s subscribed to %s", conn.RemoteAddr(), topic)
+	return true
+}
+
+// unsubscribe removes conn from all topics.
+func (h *ipcHub) unsubscribe(conn *net.UnixConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, conns := range h.subs {
+		delete(conns, conn)
+	}
+}
+
+// publish sends params to the subscribers of topic. Subscribers that cannot keep up
+// are dropped.
+func (h *ipcHub) publish(topic string, params any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for conn := range h.subs[topic] {
+		if err := writeLine(conn, ipcNotification{JsonRPC: "2.0", Method: topic, Params: params}); err != nil {
+			log.Printf("Dropping %s subscriber: %v", topic, err)
+			delete(h.subs[topic], conn)
+			conn.Close()
+		}
+	}
+}
+
+func writeLine(conn *net.UnixConn, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetWriteDeadline(time.Now().Add(ipcWriteTimeout)); err != nil {
+		return err
+	}
+	_, err = conn.Write(append(data, '\n'))
+	return err
+}
+
+// serveIPC reads commands from conn and forwards them to cmdChan until conn is
+// closed. Subscribe requests are handled by hub instead.
+func serveIPC(ctx context.Context, conn *net.UnixConn, cmdChan chan<- []byte, hub *ipcHub) {
+	defer func() {
+		hub.unsubscribe(conn)
+		conn.Close()
+		log.Println("IPC connection closed.")
+	}()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		// scanner.Bytes() may reuse its buffer, so forward a copy.
+		cmd := slices.Clone(scanner.Bytes())
+		if hub.subscribe(conn, cmd) {
+			continue
+		}
+		select {
+		case cmdChan <- cmd:
+		case <-ctx.Done():
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, net.ErrClosed) {
+		log.Printf("serveIPC: error reading from IPC socket: %v", err)
+	}
+}
@@ -1,90 +1,144 @@
 package main
 
 import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/urfave/cli/v2"
 	"github.com/wakamex/rysk-v12-cli/ryskcore" // Adjust if your fork's module path is different
 )
 
-// JsonRPCRequest needs to be defined in this package or imported if it's a shared type for the CLI.
-// writeToSocket also needs to be defined or imported for this package.
+// transferMessageFlags are the flags describing the signed fields of a transfer.
+// They are shared by `transfer` and `typed-data transfer`.
+var transferMessageFlags = []cli.Flag{
+	&cli.Int64Flag{
+		Name:     "chain_id",
+		Required: true,
+		Usage:    "chain_id",
+	},
+	&cli.StringFlag{
+		Name:     "asset",
+		Required: true,
+		Usage:    "asset address, or symbol from the chain's asset catalogue (see assets)",
+	},
+	&cli.StringFlag{
+		Name:     "amount",
+		Required: true,
+		Usage:    "amount to deposit",
+	},
+	&cli.BoolFlag{
+		Name:  "is_deposit",
+		Usage: "whether you want to deposit or withdraw",
+	},
+	unitsFlag,
+	&cli.StringSliceFlag{
+		Name:  "rpc_url",
+		Usage: "rpc url to read the asset's decimals from with --units decimal, if it is not in the asset catalogue (default: the chain's rpc urls from the registry)",
+	},
+}
 
 var transferAction = &cli.Command{
 	Name:  "transfer",
 	Usage: "request a transfer",
-	Flags: []cli.Flag{
+	Flags: joinFlags([]cli.Flag{
 		&cli.StringFlag{
 			Name:     "channel_id",
 			Required: true,
 			Usage:    "the socket id to send messages into",
 		},
-		&cli.Int64Flag{
-			Name:     "chain_id",
-			Required: true,
-			Usage:    "chain_id",
-		},
-		&cli.StringFlag{
-			Name:     "asset",
-			Required: true,
-			Usage:    "asset address",
-		},
-		&cli.StringFlag{
-			Name:     "amount",
-			Required: true,
-			Usage:    "amount to deposit",
-		},
-		&cli.BoolFlag{
-			Name:  "is_deposit",
-			Usage: "whether you want to deposit or withdraw",
-		},
-		&cli.StringFlag{
-			Name:     "nonce",
-			Required: true,
-			Usage:    "nonce to sign the message with",
-		},
-		&cli.StringFlag{
-			Name:     "private_key",
-			Required: true,
-			Usage:    "private key to sign messages with",
-		},
-	},
+	}, transferMessageFlags, messageNonceFlags, domainFlags, signingFlags),
 	Action: func(c *cli.Context) error {
 		return transferCmdFunc(c) // Renamed function
 	},
 }
 
+// transferFromContext builds an unsigned transfer from the transferMessageFlags. The
+// asset may be given by symbol. With --units decimal, the amount is scaled by the
+// asset's decimals, taken from the chain's asset catalogue or else read on-chain.
+func transferFromContext(c *cli.Context) (ryskcore.Transfer, error) {
+	t := ryskcore.Transfer{
+		ChainID:   int(c.Int64("chain_id")),
+		IsDeposit: c.Bool("is_deposit"),
+		Nonce:     c.String("nonce"),
+	}
+	var err error
+	if t.Asset, err = assetFromContext(c, t.ChainID); err != nil {
+		return t, err
+	}
+	t.Amount, err = uint256Amount(c, "amount", func() (uint8, error) {
+		if !common.IsHexAddress(t.Asset) {
+			return 0, fmt.Errorf("invalid asset address %q", t.Asset)
+		}
+		if chain, err := ryskcore.GetChain(t.ChainID); err == nil {
+			if a, err := chain.LookupAsset(t.Asset); err == nil {
+				return a.Decimals, nil
+			}
+		}
+		pool, err := dialFromContext(c, t.ChainID)
+		if err != nil {
+			return 0, err
+		}
+		defer pool.Close()
+		return ryskcore.TokenDecimals(c.Context, pool.Client(), common.HexToAddress(t.Asset))
+	})
+	return t, err
+}
+
 func transferCmdFunc(c *cli.Context) error {
 	channelID := c.String("channel_id")
-	nonce := c.String("nonce")
 	method := "withdraw"
 	if c.Bool("is_deposit") {
 		method = "deposit"
 	}
-	// Assuming JsonRPCRequest is defined in this 'main' package scope or imported.
-	payload := JsonRPCRequest{
-		JsonRPC: "2.0",
-		ID:      nonce,
-		Method:  method,
-	}
 
-	t := ryskcore.Transfer{
-		Asset:     c.String("asset"),
-		ChainID:   int(c.Int64("chain_id")),
-		Amount:    c.String("amount"),
-		IsDeposit: c.Bool("is_deposit"),
-		Nonce:     nonce,
+	t, err := transferFromContext(c)
+	if err != nil {
+		return err
+	}
+	if err := applyDomainFlags(c, t.ChainID, "Transfer"); err != nil {
+		return err
+	}
+	s, err := signerFromContext(c)
+	if err != nil {
+		return err
+	}
+	defer s.clear()
+	// A transfer is from the signing account, so a nonce can only be generated,
+	// and a nonce given with --nonce checked before signing, with a private key.
+	// An imported signature's nonce is checked once its signer is recovered.
+	account, known := s.address()
+	switch {
+	case t.Nonce == "" && !known:
+		return fmt.Errorf("--nonce is required with --signature, as the signature covers it")
+	case t.Nonce == "":
+		if t.Nonce, err = nextMessageNonce(c, account); err != nil {
+			return err
+		}
+	case known:
+		if err := useMessageNonce(c, account, t.Nonce); err != nil {
+			return err
+		}
 	}
 
-	msgHash, _, err := ryskcore.CreateTransferMessage(t)
+	msgHash, err := ryskcore.TransferHash(t)
 	if err != nil {
 		return err
 	}
-	sig, err := ryskcore.Sign(msgHash, c.String("private_key"))
+	sig, signer, err := s.sign(msgHash.Bytes())
 	if err != nil {
 		return err
 	}
+	if !known {
+		if err := useMessageNonce(c, signer, t.Nonce); err != nil {
+			return err
+		}
+	}
 	t.Signature = sig
-	payload.Params = t
 
-	// Assuming writeToSocket is defined in this 'main' package scope or imported.
-	return writeToSocket(channelID, payload)
+	return writeToSocket(channelID, JsonRPCRequest{
+		JsonRPC: "2.0",
+		ID:      t.Nonce,
+		Method:  method,
+		Params:  t,
+	})
 }
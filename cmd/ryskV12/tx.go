@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/goccy/go-json"
+	"github.com/urfave/cli/v2"
+
+	"github.com/wakamex/rysk-v12-cli/ryskcore"
+)
+
+// gweiDecimals scales the gwei fee flags to wei.
+const gweiDecimals = 9
+
+// txFlags control how on-chain transactions are built and sent.
+var txFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "max_fee",
+		Usage: "max fee per gas in gwei (gas price on chains without EIP-1559), default: 2x base fee + priority fee",
+	},
+	&cli.StringFlag{
+		Name:  "max_priority_fee",
+		Usage: "max priority fee per gas in gwei, default: suggested by the node; rejected on chains without EIP-1559",
+	},
+	&cli.StringFlag{
+		Name:  "gas_price_cap",
+		Usage: "refuse to send if the fee per gas could exceed this many gwei",
+	},
+	&cli.BoolFlag{
+		Name:    "no_wait",
+		Aliases: []string{"no-wait"},
+		Usage:   "print the transaction hash as soon as it is sent, without waiting for it to be mined",
+	},
+	&cli.BoolFlag{
+		Name:    "dry_run",
+		Aliases: []string{"dry-run"},
+		Usage:   "simulate the transaction against the pending block and print the call, gas and fee, without signing or sending it",
+	},
+	&cli.DurationFlag{
+		Name:  "timeout",
+		Usage: "give up waiting for the transaction to be mined after this long, e.g. 2m (default: wait forever)",
+	},
+}
+
+var txAction = &cli.Command{
+	Name:  "tx",
+	Usage: "inspect or replace a sent transaction",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "status",
+			Usage:     "show the status of a transaction",
+			ArgsUsage: "<hash>",
+			Flags:     joinFlags(txChainFlags, rpcFlags),
+			Action: func(c *cli.Context) error {
+				return txStatusCmdFunc(c)
+			},
+		},
+		{
+			Name:      "speedup",
+			Usage:     "resubmit a pending transaction with the same nonce and higher fees",
+			ArgsUsage: "<hash>",
+			Flags:     joinFlags(txChainFlags, rpcFlags, privateKeyFlags, txFlags),
+			Action: func(c *cli.Context) error {
+				return txReplaceCmdFunc(c, (*ryskcore.Account).SpeedUp)
+			},
+		},
+		{
+			Name:      "cancel",
+			Usage:     "replace a pending transaction with a zero value transfer to self",
+			ArgsUsage: "<hash>",
+			Flags:     joinFlags(txChainFlags, rpcFlags, privateKeyFlags, txFlags),
+			Action: func(c *cli.Context) error {
+				return txReplaceCmdFunc(c, (*ryskcore.Account).Cancel)
+			},
+		},
+	},
+}
+
+var txChainFlags = []cli.Flag{
+	&cli.Int64Flag{
+		Name:     "chain_id",
+		Required: true,
+		Usage:    "chain_id",
+	},
+}
+
+// txOptionsFromContext builds ryskcore.TxOptions from the txFlags. Nonces are assigned
+// through a nonce manager in the state directory, shared by every command.
+func txOptionsFromContext(c *cli.Context) (ryskcore.TxOptions, error) {
+	var opts ryskcore.TxOptions
+	for name, dst := range map[string]**big.Int{
+		"max_fee":          &opts.MaxFee,
+		"max_priority_fee": &opts.MaxPriorityFee,
+		"gas_price_cap":    &opts.GasPriceCap,
+	} {
+		if !c.IsSet(name) {
+			continue
+		}
+		wei, err := ryskcore.ParseUnits(c.String(name), gweiDecimals)
+		if err != nil {
+			return opts, fmt.Errorf("--%s: %w", name, err)
+		}
+		if wei.Sign() < 0 {
+			return opts, fmt.Errorf("--%s must not be negative", name)
+		}
+		*dst = wei
+	}
+
+	opts.DryRun = c.Bool("dry_run")
+	opts.NoWait = c.Bool("no_wait")
+	opts.Timeout = c.Duration("timeout")
+
+	path, err := stateFile(c, "nonces.json")
+	if err != nil {
+		return opts, err
+	}
+	opts.Nonces, err = ryskcore.NewNonceManager(path)
+	return opts, err
+}
+
+// exitReverted is the exit code of a command whose transaction reverted, or would
+// revert on a dry run.
+const exitReverted = 3
+
+// exitCode returns the exit code of a command that failed with err.
+func exitCode(err error) int {
+	var reverted *ryskcore.RevertError
+	if errors.As(err, &reverted) {
+		return exitReverted
+	}
+	return 1
+}
+
+// printTxResult prints the result of a transaction, or the simulation of a dry run, as
+// JSON if there is one, and passes err through so that reverted transactions still
+// fail the command.
+func printTxResult(result *ryskcore.TxResult, err error) error {
+	if result != nil {
+		var v any = result
+		if result.Simulation != nil {
+			v = result.Simulation
+		}
+		out, merr := json.Marshal(v)
+		if merr != nil {
+			return merr
+		}
+		fmt.Println(string(out))
+	}
+	return err
+}
+
+func txStatusCmdFunc(c *cli.Context) error {
+	hash, err := txHashArg(c)
+	if err != nil {
+		return err
+	}
+
+	pool, err := dialFromContext(c, int(c.Int64("chain_id")))
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	client := pool.Client()
+
+	return printTxResult(ryskcore.TxStatus(c.Context, client, hash))
+}
+
+func txReplaceCmdFunc(c *cli.Context, replace func(*ryskcore.Account, context.Context, int, ryskcore.Backend, common.Hash, ryskcore.TxOptions) (*ryskcore.TxResult, error)) error {
+	hash, err := txHashArg(c)
+	if err != nil {
+		return err
+	}
+
+	account, err := accountFromContext(c)
+	if err != nil {
+		return err
+	}
+	defer account.Clear()
+
+	txOpts, err := txOptionsFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	pool, err := dialFromContext(c, int(c.Int64("chain_id")))
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	client := pool.Client()
+
+	return printTxResult(replace(&account, c.Context, int(c.Int64("chain_id")), client, hash, txOpts))
+}
+
+// txHashArg parses the transaction hash given as the first argument.
+func txHashArg(c *cli.Context) (common.Hash, error) {
+	arg := c.Args().First()
+	if b, err := hexutil.Decode(arg); err != nil || len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid transaction hash %q", arg)
+	}
+	return common.HexToHash(arg), nil
+}
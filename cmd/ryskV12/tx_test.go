@@ -0,0 +1,25 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/wakamex/rysk-v12-cli/ryskcore"
+)
+
+func TestExitCode(t *testing.T) {
+	reverted := &ryskcore.RevertError{Result: &ryskcore.TxResult{Status: ryskcore.TxStatusReverted, RevertReason: "not expired"}}
+	for _, tt := range []struct {
+		err  error
+		want int
+	}{
+		{reverted, exitReverted},
+		{fmt.Errorf("settle: %w", reverted), exitReverted},
+		{errors.New("connection refused"), 1},
+	} {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
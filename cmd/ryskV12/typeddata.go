@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/goccy/go-json"
+	"github.com/urfave/cli/v2"
+
+	"github.com/wakamex/rysk-v12-cli/ryskcore"
+)
+
+// typedDataOutput is what `typed-data` prints: the eth_signTypedData_v4 payload
+// for an external wallet, and the digest the wallet is expected to sign.
+type typedDataOutput struct {
+	TypedData signTypedDataV4 `json:"typedData"`
+	Digest    string          `json:"digest"`
+}
+
+// signTypedDataV4 mirrors apitypes.TypedData, but encodes the domain through
+// TypedDataDomain.Map so that unset fields such as salt are omitted instead of
+// being sent to the wallet as empty strings.
+type signTypedDataV4 struct {
+	Types       apitypes.Types            `json:"types"`
+	PrimaryType string                    `json:"primaryType"`
+	Domain      map[string]interface{}    `json:"domain"`
+	Message     apitypes.TypedDataMessage `json:"message"`
+}
+
+var typedDataAction = &cli.Command{
+	Name:  "typed-data",
+	Usage: "print the EIP-712 typed data of a message for signing with an external wallet",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "quote",
+			Usage: "typed data of a quote",
+			Flags: joinFlags(quoteMessageFlags, []cli.Flag{requiredNonceFlag}, domainFlags),
+			Action: func(c *cli.Context) error {
+				q, err := quoteFromContext(c)
+				if err != nil {
+					return err
+				}
+				if err := applyDomainFlags(c, q.ChainID, "Quote"); err != nil {
+					return err
+				}
+				msgHash, typedData, err := ryskcore.CreateQuoteMessage(q)
+				if err != nil {
+					return err
+				}
+				return printTypedData(msgHash, typedData)
+			},
+		},
+		{
+			Name:  "transfer",
+			Usage: "typed data of a transfer",
+			Flags: joinFlags(transferMessageFlags, []cli.Flag{requiredNonceFlag}, domainFlags),
+			Action: func(c *cli.Context) error {
+				t, err := transferFromContext(c)
+				if err != nil {
+					return err
+				}
+				if err := applyDomainFlags(c, t.ChainID, "Transfer"); err != nil {
+					return err
+				}
+				msgHash, typedData, err := ryskcore.CreateTransferMessage(t)
+				if err != nil {
+					return err
+				}
+				return printTypedData(msgHash, typedData)
+			},
+		},
+	},
+}
+
+func printTypedData(msgHash []byte, typedData *apitypes.TypedData) error {
+	out, err := json.MarshalIndent(typedDataOutput{
+		TypedData: signTypedDataV4{
+			Types:       typedData.Types,
+			PrimaryType: typedData.PrimaryType,
+			Domain:      typedData.Domain.Map(),
+			Message:     typedData.Message,
+		},
+		Digest: hexutil.Encode(msgHash),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
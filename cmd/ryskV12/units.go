@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+
+	"github.com/wakamex/rysk-v12-cli/ryskcore"
+)
+
+const (
+	unitsBase    = "base"
+	unitsDecimal = "decimal"
+)
+
+// unitsFlag selects how amount flags are read.
+var unitsFlag = &cli.StringFlag{
+	Name:  "units",
+	Value: unitsBase,
+	Usage: "how amounts are given: \"base\" units, in decimal or 0x hex, or \"decimal\" amounts like 1500.25 or 2.5e3, scaled by the decimals of the asset or the protocol",
+}
+
+// decimalUnits reports whether amounts are given as decimals rather than base units.
+func decimalUnits(c *cli.Context) (bool, error) {
+	switch units := c.String("units"); units {
+	case unitsBase:
+		return false, nil
+	case unitsDecimal:
+		return true, nil
+	default:
+		return false, fmt.Errorf("--units must be %q or %q, not %q", unitsBase, unitsDecimal, units)
+	}
+}
+
+// parseAmount parses the value of the amount flag name as set by --units. In
+// decimal units it is scaled by decimals, which is only called then and may be nil
+// otherwise. Base units may also be given in 0x hex. Negative amounts are rejected.
+func parseAmount(c *cli.Context, name string, decimals func() (uint8, error)) (*big.Int, error) {
+	value := c.String(name)
+	decimal, err := decimalUnits(c)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := strings.TrimSpace(value); !decimal && (strings.HasPrefix(trimmed, "0x") || strings.HasPrefix(trimmed, "0X")) {
+		amount, err := ryskcore.ParseUint256(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("--%s: %w", name, err)
+		}
+		return amount.Big(), nil
+	}
+	var d uint8
+	if decimal {
+		if d, err = decimals(); err != nil {
+			return nil, err
+		}
+	}
+	amount, err := ryskcore.ParseUnits(value, d)
+	if err != nil {
+		return nil, fmt.Errorf("--%s: %w", name, err)
+	}
+	if amount.Sign() < 0 {
+		return nil, fmt.Errorf("--%s %q is negative", name, value)
+	}
+	return amount, nil
+}
+
+// tokenDecimals returns a function reading the decimals of token once.
+func tokenDecimals(c *cli.Context, caller bind.ContractCaller, token common.Address) func() (uint8, error) {
+	return sync.OnceValues(func() (uint8, error) {
+		return ryskcore.TokenDecimals(c.Context, caller, token)
+	})
+}
+
+// quoteAmount parses the quote field flag name as set by --units: base units as is,
+// or a decimal scaled by parse to the protocol's fixed point.
+func quoteAmount(c *cli.Context, name string, parse func(string) (ryskcore.Uint256, error)) (ryskcore.Uint256, error) {
+	decimal, err := decimalUnits(c)
+	if err != nil {
+		return ryskcore.Uint256{}, err
+	}
+	if decimal {
+		return parse(c.String(name))
+	}
+	return uint256Amount(c, name, nil)
+}
+
+// uint256Amount is parseAmount for amounts that must fit in a uint256.
+func uint256Amount(c *cli.Context, name string, decimals func() (uint8, error)) (ryskcore.Uint256, error) {
+	amount, err := parseAmount(c, name, decimals)
+	if err != nil {
+		return ryskcore.Uint256{}, err
+	}
+	u, err := ryskcore.NewUint256(amount)
+	if err != nil {
+		return ryskcore.Uint256{}, fmt.Errorf("--%s: %w", name, err)
+	}
+	return u, nil
+}
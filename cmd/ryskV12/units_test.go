@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/goccy/go-json"
+	"github.com/urfave/cli/v2"
+)
+
+func TestParseAmount(t *testing.T) {
+	flags := []cli.Flag{unitsFlag, &cli.StringFlag{Name: "amount"}}
+	sixDecimals := func() (uint8, error) { return 6, nil }
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--amount", "1500250000"}, "1500250000"},
+		{[]string{"--amount", "0x596bff90"}, "1500250000"},
+		{[]string{"--amount", "0X596BFF90"}, "1500250000"},
+		{[]string{"--amount", "1.5e3"}, "1500"},
+		{[]string{"--units", "decimal", "--amount", "1500.25"}, "1500250000"},
+	} {
+		c := newTestContext(t, flags, tt.args...)
+		got, err := parseAmount(c, "amount", sixDecimals)
+		if err != nil || got.String() != tt.want {
+			t.Errorf("parseAmount(%v) = %v, %v, want %s", tt.args, got, err, tt.want)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"--amount", "-1"},
+		{"--amount", "1.5"},
+		{"--amount", "0x"},
+		{"--amount", "0xg1"},
+		{"--amount", "0x1" + strings.Repeat("0", 64)},
+		{"--units", "decimal", "--amount", "0x10"},
+		{"--units", "hex", "--amount", "1"},
+	} {
+		c := newTestContext(t, flags, args...)
+		if got, err := parseAmount(c, "amount", sixDecimals); err == nil {
+			t.Errorf("parseAmount(%v) = %s, want an error", args, got)
+		}
+	}
+
+	// Decimals are only read for decimal amounts.
+	c := newTestContext(t, flags, "--amount", "0x10")
+	failing := func() (uint8, error) { return 0, errors.New("no decimals") }
+	if got, err := parseAmount(c, "amount", failing); err != nil || got.Int64() != 16 {
+		t.Errorf("parseAmount(0x10) = %v, %v, want 16", got, err)
+	}
+}
+
+func TestTransferAmountUsesCatalogueDecimals(t *testing.T) {
+	// The rpc url is unreachable, so decimals can only come from the catalogue.
+	args := []string{"--chain_id", "8453", "--units", "decimal", "--amount", "1.5", "--rpc_url", "http://127.0.0.1:1"}
+	for asset, want := range map[string]string{
+		"usdc": "1500000",
+		"WETH": "1500000000000000000",
+		"0x4200000000000000000000000000000000000006": "1500000000000000000",
+	} {
+		c := newTestContext(t, transferMessageFlags, append([]string{"--asset", asset}, args...)...)
+		tr, err := transferFromContext(c)
+		if err != nil || tr.Amount.String() != want {
+			t.Errorf("transferFromContext(--asset %s) amount = %v, %v, want %s", asset, tr.Amount, err, want)
+		}
+	}
+
+	c := newTestContext(t, transferMessageFlags, append([]string{"--asset", "0x00000000000000000000000000000000000000e1"}, args...)...)
+	if tr, err := transferFromContext(c); err == nil {
+		t.Errorf("transferFromContext(uncatalogued asset) = %+v, want an error reading its decimals", tr)
+	}
+}
+
+func TestTransferAmountReadsUncataloguedDecimals(t *testing.T) {
+	for _, tt := range []struct {
+		chainID, asset, want string
+	}{
+		// Catalogued assets do not read the node.
+		{"8453", "usdc", "1500000"},
+		{"8453", "0x00000000000000000000000000000000000000e1", "150000000"},
+		// Testnets have no catalogue, but their strike asset is known.
+		{"84532", "strike", "150000000"},
+	} {
+		url := decimalsNode(t, tt.chainID, 8)
+		c := newTestContext(t, transferMessageFlags, "--chain_id", tt.chainID, "--units", "decimal", "--amount", "1.5", "--rpc_url", url, "--asset", tt.asset)
+		tr, err := transferFromContext(c)
+		if err != nil || tr.Amount.String() != tt.want {
+			t.Errorf("transferFromContext(--chain_id %s --asset %s) amount = %v, %v, want %s", tt.chainID, tt.asset, tr.Amount, err, tt.want)
+		}
+	}
+}
+
+// decimalsNode serves a JSON-RPC node on chainID where every token has decimals.
+func decimalsNode(t *testing.T, chainID string, decimals byte) string {
+	t.Helper()
+	id, err := strconv.ParseUint(chainID, 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "eth_chainId":
+			resp["result"] = hexutil.Uint64(id)
+		case "eth_blockNumber":
+			resp["result"] = hexutil.Uint64(1)
+		case "eth_call":
+			resp["result"] = hexutil.Bytes(common.LeftPadBytes([]byte{decimals}, 32))
+		default:
+			resp["error"] = map[string]any{"code": -32601, "message": "method not found"}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(node.Close)
+	return node.URL
+}
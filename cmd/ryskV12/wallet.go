@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/goccy/go-json"
+	"github.com/urfave/cli/v2"
+
+	"github.com/wakamex/rysk-v12-cli/ryskcore"
+)
+
+var walletAction = &cli.Command{
+	Name:  "wallet",
+	Usage: "show native balance, strike asset balance and allowances of accounts",
+	Flags: joinFlags([]cli.Flag{
+		&cli.Int64Flag{
+			Name:     "chain_id",
+			Required: true,
+			Usage:    "chain_id",
+		},
+		&cli.StringSliceFlag{
+			Name:     "account",
+			Required: true,
+			Usage:    "address of the account to inspect, repeat or comma separate for several",
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "print the result as JSON",
+		},
+		&cli.IntFlag{
+			Name:  "rpc_quorum",
+			Usage: "read from every rpc url and require this many to agree on each balance and allowance",
+		},
+	}, rpcFlags),
+	Action: func(c *cli.Context) error {
+		return walletCmdFunc(c)
+	},
+}
+
+// walletOutput is a ryskcore.Wallet with amounts formatted using their decimals.
+type walletOutput struct {
+	Account             string `json:"account"`
+	ChainID             int    `json:"chainId"`
+	StrikeAsset         string `json:"strikeAsset"`
+	Native              string `json:"native"`
+	StrikeBalance       string `json:"strikeBalance"`
+	MMarketAllowance    string `json:"mmarketAllowance"`
+	MarginPoolAllowance string `json:"marginPoolAllowance"`
+}
+
+func walletCmdFunc(c *cli.Context) error {
+	chainID := int(c.Int64("chain_id"))
+	var accounts []common.Address
+	for _, account := range c.StringSlice("account") {
+		if !common.IsHexAddress(account) {
+			return fmt.Errorf("invalid account address %q", account)
+		}
+		accounts = append(accounts, common.HexToAddress(account))
+	}
+
+	pool, err := dialFromContext(c, chainID)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	client := pool.Client()
+
+	var backend ryskcore.WalletBackend = client
+	if n := c.Int("rpc_quorum"); n > 0 {
+		if backend, err = pool.Quorum(c.Context, n); err != nil {
+			return err
+		}
+	}
+
+	wallets, err := ryskcore.InspectWallets(c.Context, backend, chainID, accounts)
+	if err != nil {
+		return err
+	}
+
+	out := make([]walletOutput, len(wallets))
+	for i, w := range wallets {
+		out[i] = walletOutput{
+			Account:             w.Account.Hex(),
+			ChainID:             w.ChainID,
+			StrikeAsset:         w.StrikeAsset.Hex(),
+			Native:              ryskcore.FormatUnits(w.Native, ryskcore.NativeDecimals),
+			StrikeBalance:       ryskcore.FormatUnits(w.StrikeBalance, w.StrikeDecimals),
+			MMarketAllowance:    formatAllowance(w.MMarketAllowance, w.StrikeDecimals),
+			MarginPoolAllowance: formatAllowance(w.MarginPoolAllowance, w.StrikeDecimals),
+		}
+	}
+
+	if c.Bool("json") {
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACCOUNT\tNATIVE\tSTRIKE BALANCE\tMMARKET ALLOWANCE\tMARGIN POOL ALLOWANCE")
+	for _, o := range out {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", o.Account, o.Native, o.StrikeBalance, o.MMarketAllowance, o.MarginPoolAllowance)
+	}
+	return w.Flush()
+}
+
+// formatAllowance formats an allowance, showing the conventional unlimited approval as "max".
+func formatAllowance(amount *big.Int, decimals uint8) string {
+	if amount.Cmp(math.MaxBig256) == 0 {
+		return "max"
+	}
+	return ryskcore.FormatUnits(amount, decimals)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/goccy/go-json"
+	"github.com/urfave/cli/v2"
+
+	"github.com/wakamex/rysk-v12-cli/ryskcore"
+)
+
+var watchChainAction = &cli.Command{
+	Name:  "watch-chain",
+	Usage: "stream ERC20 Transfer and Approval events of maker accounts, MarginPool and MMarket as NDJSON",
+	Flags: joinFlags([]cli.Flag{
+		&cli.Int64Flag{
+			Name:     "chain_id",
+			Required: true,
+			Usage:    "chain_id",
+		},
+		&cli.StringSliceFlag{
+			Name:  "account",
+			Usage: "maker account to watch in addition to MarginPool and MMarket, repeat or comma separate for several",
+		},
+		&cli.StringSliceFlag{
+			Name:  "asset",
+			Value: cli.NewStringSlice("strike"),
+			Usage: "token to watch: \"strike\", a symbol from the chain's asset catalogue, or a token address, repeat or comma separate for several",
+		},
+		&cli.Uint64Flag{
+			Name:  "from_block",
+			Usage: "first block to scan, to backfill past events (default: the latest confirmed block)",
+		},
+		&cli.Uint64Flag{
+			Name:  "to_block",
+			Usage: "last block to scan, then exit (default: follow the chain)",
+		},
+		&cli.Uint64Flag{
+			Name:  "confirmations",
+			Value: 2,
+			Usage: "blocks to wait on top of a block before reporting its events",
+		},
+		&cli.DurationFlag{
+			Name:  "poll_interval",
+			Value: ryskcore.DefaultWatchPollInterval,
+			Usage: "how often to check for new blocks",
+		},
+	}, rpcFlags),
+	Action: func(c *cli.Context) error {
+		return watchChainCmdFunc(c)
+	},
+}
+
+func watchChainCmdFunc(c *cli.Context) error {
+	chain, err := ryskcore.GetChain(int(c.Int64("chain_id")))
+	if err != nil {
+		return err
+	}
+
+	cfg := ryskcore.WatchConfig{
+		Confirmations: c.Uint64("confirmations"),
+		PollInterval:  c.Duration("poll_interval"),
+	}
+	for _, asset := range c.StringSlice("asset") {
+		token, err := chain.Asset(asset)
+		if err != nil {
+			return err
+		}
+		cfg.Tokens = append(cfg.Tokens, token)
+	}
+	for _, account := range c.StringSlice("account") {
+		if !common.IsHexAddress(account) {
+			return fmt.Errorf("invalid account address %q", account)
+		}
+		cfg.Parties = append(cfg.Parties, common.HexToAddress(account))
+	}
+	for _, contract := range []common.Address{chain.MarginPool, chain.MMarket} {
+		if contract != ryskcore.ZeroAddress {
+			cfg.Parties = append(cfg.Parties, contract)
+		}
+	}
+	if len(cfg.Parties) == 0 {
+		return fmt.Errorf("chain %d has no MarginPool or MMarket configured, pass --account", chain.ChainID)
+	}
+	if c.IsSet("from_block") {
+		cfg.FromBlock = new(big.Int).SetUint64(c.Uint64("from_block"))
+	}
+	if c.IsSet("to_block") {
+		cfg.ToBlock = new(big.Int).SetUint64(c.Uint64("to_block"))
+	}
+
+	pool, err := dialFromContext(c, chain.ChainID)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	client := pool.Client()
+
+	return ryskcore.WatchEvents(c.Context, client, cfg, func(e ryskcore.ChainEvent) error {
+		out, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	})
+}
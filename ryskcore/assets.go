@@ -0,0 +1,88 @@
+package ryskcore
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AssetRole is what the protocol uses an asset for.
+type AssetRole string
+
+const (
+	// RoleUnderlying is an asset options are written on, such as WETH.
+	RoleUnderlying AssetRole = "underlying"
+	// RoleStrike is the asset strikes and premiums are paid in, such as USDC.
+	RoleStrike AssetRole = "strike"
+)
+
+// AssetInfo is an entry of a chain's asset catalogue.
+type AssetInfo struct {
+	Symbol   string         `json:"symbol" yaml:"symbol"`
+	Address  common.Address `json:"address" yaml:"address"`
+	Decimals uint8          `json:"decimals" yaml:"decimals"`
+	Role     AssetRole      `json:"role" yaml:"role"`
+}
+
+// ResolveAsset returns the address of asset on chainID as Chain.Asset does. An address
+// is returned as is, even for a chain that is not registered.
+func ResolveAsset(chainID int, asset string) (common.Address, error) {
+	if common.IsHexAddress(asset) {
+		return common.HexToAddress(asset), nil
+	}
+	chain, err := GetChain(chainID)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return chain.Asset(asset)
+}
+
+// LookupAsset returns the catalogue entry with the given address, or with the given
+// symbol, matched case-insensitively. A symbol shared by several entries with
+// different addresses is ambiguous and rejected.
+func (c Chain) LookupAsset(symbolOrAddress string) (AssetInfo, error) {
+	if common.IsHexAddress(symbolOrAddress) {
+		address := common.HexToAddress(symbolOrAddress)
+		for _, a := range c.Assets {
+			if a.Address == address {
+				return a, nil
+			}
+		}
+		return AssetInfo{}, fmt.Errorf("%s is not in the asset catalogue of chain %d", symbolOrAddress, c.ChainID)
+	}
+
+	var matches []AssetInfo
+	for _, a := range c.Assets {
+		if strings.EqualFold(a.Symbol, symbolOrAddress) && !slices.ContainsFunc(matches, func(m AssetInfo) bool { return m.Address == a.Address }) {
+			matches = append(matches, a)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return AssetInfo{}, fmt.Errorf("unknown asset %q on chain %d, see `ryskV12 assets --chain_id %d`", symbolOrAddress, c.ChainID, c.ChainID)
+	case 1:
+		return matches[0], nil
+	default:
+		addresses := make([]string, len(matches))
+		for i, m := range matches {
+			addresses[i] = m.Address.Hex()
+		}
+		return AssetInfo{}, fmt.Errorf("asset symbol %q is ambiguous on chain %d, use one of the addresses %s", symbolOrAddress, c.ChainID, strings.Join(addresses, ", "))
+	}
+}
+
+func checkAssets(assets []AssetInfo) error {
+	for _, a := range assets {
+		switch {
+		case a.Symbol == "" || common.IsHexAddress(a.Symbol) || strings.EqualFold(a.Symbol, "strike"):
+			return fmt.Errorf("asset %s has an invalid symbol %q", a.Address.Hex(), a.Symbol)
+		case a.Address == ZeroAddress:
+			return fmt.Errorf("asset %s has no address", a.Symbol)
+		case a.Role != RoleUnderlying && a.Role != RoleStrike:
+			return fmt.Errorf("asset %s has role %q, expected %q or %q", a.Symbol, a.Role, RoleUnderlying, RoleStrike)
+		}
+	}
+	return nil
+}
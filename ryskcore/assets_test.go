@@ -0,0 +1,98 @@
+package ryskcore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	testWETH = common.HexToAddress("0x00000000000000000000000000000000000000e1")
+	testWBTC = common.HexToAddress("0x00000000000000000000000000000000000000e2")
+	testUSDC = common.HexToAddress("0x00000000000000000000000000000000000000c1")
+)
+
+var testCatalogueChain = Chain{
+	ChainID:   31337,
+	Addresses: Addresses{StrikeAsset: testUSDC},
+	Assets: []AssetInfo{
+		{Symbol: "USDC", Address: testUSDC, Decimals: 6, Role: RoleStrike},
+		{Symbol: "WETH", Address: testWETH, Decimals: 18, Role: RoleUnderlying},
+		{Symbol: "weth", Address: testWETH, Decimals: 18, Role: RoleUnderlying},
+		{Symbol: "WBTC", Address: testWBTC, Decimals: 8, Role: RoleUnderlying},
+		{Symbol: "wbtc", Address: common.HexToAddress("0x00000000000000000000000000000000000000b2"), Decimals: 8, Role: RoleUnderlying},
+	},
+}
+
+func TestLookupAsset(t *testing.T) {
+	c := testCatalogueChain
+	for _, name := range []string{"WETH", "weth", "Weth", testWETH.Hex(), strings.ToLower(testWETH.Hex())} {
+		a, err := c.LookupAsset(name)
+		if err != nil || a.Address != testWETH || a.Decimals != 18 || a.Role != RoleUnderlying {
+			t.Errorf("LookupAsset(%s) = %+v, %v", name, a, err)
+		}
+	}
+	for name, want := range map[string]string{
+		"WBTC":            "ambiguous",
+		"HYPE":            "unknown asset",
+		ZeroAddress.Hex(): "not in the asset catalogue",
+		"0x1234":          "unknown asset",
+	} {
+		if _, err := c.LookupAsset(name); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LookupAsset(%s) = %v, want an error containing %q", name, err, want)
+		}
+	}
+}
+
+func TestChainAssetBySymbol(t *testing.T) {
+	c := testCatalogueChain
+	for name, want := range map[string]common.Address{
+		"strike":       testUSDC,
+		"usdc":         testUSDC,
+		"WETH":         testWETH,
+		testWETH.Hex(): testWETH,
+	} {
+		if got, err := c.Asset(name); err != nil || got != want {
+			t.Errorf("Asset(%s) = %s, %v, want %s", name, got.Hex(), err, want.Hex())
+		}
+	}
+	if _, err := c.Asset("wbtc"); err == nil {
+		t.Error("Asset(wbtc) succeeded for an ambiguous symbol")
+	}
+	unregistered := common.HexToAddress("0x00000000000000000000000000000000000000ff")
+	if got, err := c.Asset(unregistered.Hex()); err != nil || got != unregistered {
+		t.Errorf("Asset(%s) = %s, %v, want the address as is", unregistered.Hex(), got.Hex(), err)
+	}
+}
+
+func TestResolveAsset(t *testing.T) {
+	unregistered := "0x00000000000000000000000000000000000000fF"
+	if got, err := ResolveAsset(CHAIN_ID_BASE, unregistered); err != nil || got != common.HexToAddress(unregistered) {
+		t.Errorf("ResolveAsset(%s) = %s, %v", unregistered, got.Hex(), err)
+	}
+	usdc, err := ResolveAsset(CHAIN_ID_BASE, "usdc")
+	if err != nil || usdc != CHAINS[CHAIN_ID_BASE].StrikeAsset {
+		t.Errorf("ResolveAsset(usdc) = %s, %v, want the strike asset", usdc.Hex(), err)
+	}
+	if _, err := ResolveAsset(42, "USDC"); err == nil {
+		t.Error("ResolveAsset succeeded on an unknown chain")
+	}
+}
+
+func TestCheckAssets(t *testing.T) {
+	for name, a := range map[string]AssetInfo{
+		"no symbol":      {Address: testWETH, Role: RoleUnderlying},
+		"address symbol": {Symbol: testWETH.Hex(), Address: testWETH, Role: RoleUnderlying},
+		"strike symbol":  {Symbol: "Strike", Address: testUSDC, Role: RoleStrike},
+		"no address":     {Symbol: "WETH", Role: RoleUnderlying},
+		"bad role":       {Symbol: "WETH", Address: testWETH, Role: "collateral"},
+	} {
+		if err := checkAssets([]AssetInfo{a}); err == nil {
+			t.Errorf("%s: checkAssets succeeded", name)
+		}
+	}
+	if err := checkAssets(testCatalogueChain.Assets); err != nil {
+		t.Errorf("checkAssets(testCatalogueChain) = %v", err)
+	}
+}
@@ -0,0 +1,372 @@
+package ryskcore
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/program"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+// testChainID is the chain ID of go-ethereum's simulated backend.
+const testChainID = 1337
+
+// testTokenBalance is the test account's initial balance of the mock ERC20.
+var testTokenBalance = big.NewInt(1_000_000e6)
+
+// mockMMarketABI is the ABI of the mock MMarket: settle(expiry) reverts with "not
+// expired" before expiry, and records the settlement otherwise. deposit(asset,
+// amount) pulls amount of asset from the caller, as a spender does.
+const mockMMarketABI = `[
+	{"type":"function","name":"deposit","stateMutability":"nonpayable","inputs":[{"name":"asset","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[]},
+	{"type":"function","name":"settle","stateMutability":"nonpayable","inputs":[{"name":"expiry","type":"uint256"}],"outputs":[]},
+	{"type":"function","name":"settled","stateMutability":"view","inputs":[{"name":"expiry","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}
+]`
+
+// testChain is a simulated chain with a funded account, a mock ERC20 and a mock
+// MMarket. Blocks are mined every few milliseconds until the test ends or
+// pauseMining is called.
+type testChain struct {
+	backend *simulated.Backend
+	client  simulated.Client
+	account *Account
+	token   common.Address
+	mmarket common.Address
+
+	mmarketABI abi.ABI
+
+	mu     sync.Mutex
+	paused bool
+}
+
+func newTestChain(t *testing.T) *testChain {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := &Account{Public: crypto.PubkeyToAddress(key.PublicKey), Private: key}
+	erc20ABI, err := IERC20MetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		account.Public: {Balance: new(big.Int).Lsh(big.NewInt(1), 100)},
+	})
+	chain := &testChain{backend: backend, client: backend.Client(), account: account}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				chain.mu.Lock()
+				if !chain.paused {
+					backend.Commit()
+				}
+				chain.mu.Unlock()
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		close(done)
+		<-stopped
+		backend.Close()
+	})
+
+	chain.token = chain.deploy(t, mockERC20(erc20ABI), map[common.Hash]common.Hash{
+		common.BytesToHash(account.Public.Bytes()): common.BigToHash(testTokenBalance),
+	})
+	chain.mmarketABI, err = abi.JSON(strings.NewReader(mockMMarketABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.mmarket = chain.deploy(t, mockMMarket(chain.mmarketABI), nil)
+	return chain
+}
+
+// pauseMining stops mining blocks, so that transactions stay pending until the test
+// calls backend.Commit.
+func (c *testChain) pauseMining() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paused = true
+}
+
+// deploy deploys runtime code with the given initial storage, and waits for it to be
+// mined.
+func (c *testChain) deploy(t *testing.T, runtime []byte, storage map[common.Hash]common.Hash) common.Address {
+	t.Helper()
+	ctx := context.Background()
+	opts, err := c.account.newTransactionOpts(ctx, testChainID, c.client, TxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	constructor := program.New()
+	for slot, value := range storage {
+		constructor.Sstore(slot, value)
+	}
+	initcode := constructor.ReturnViaCodeCopy(runtime).Bytes()
+	address, tx, _, err := bind.DeployContract(opts, abi.ABI{}, initcode, c.client)
+	if err != nil {
+		t.Fatalf("deploy: %v", err)
+	}
+	if _, err := bind.WaitDeployed(ctx, c.client, tx); err != nil {
+		t.Fatalf("deploy: %v", err)
+	}
+	return address
+}
+
+// transactMMarket calls method of the mock MMarket from the account, as a command
+// would call the real one.
+func (c *testChain) transactMMarket(ctx context.Context, txOpts TxOptions, method string, args ...any) (*TxResult, error) {
+	mmarket := bind.NewBoundContract(c.mmarket, c.mmarketABI, c.client, c.client, c.client)
+	tx, err := c.account.transact(ctx, testChainID, c.client, txOpts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return mmarket.Transact(opts, method, args...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.account.complete(ctx, c.client, tx, txOpts)
+}
+
+// The name and version of the mock ERC20's EIP-712 domain. The version is not the
+// DefaultPermitVersion, so that permits only verify if it is read from the token.
+const (
+	testTokenName    = "Mock USD"
+	testTokenVersion = "2"
+)
+
+// mockERC20 assembles an ERC20 with 6 decimals and EIP-2612 permits, which spenders
+// can pull funds from but holders cannot transfer: decimals, balanceOf, allowance,
+// approve, which emits Approval, transferFrom, which checks the allowance and
+// balance and emits Transfer, name, version, nonces, DOMAIN_SEPARATOR and permit,
+// which checks the deadline and the owner's signature.
+func mockERC20(parsed *abi.ABI) []byte {
+	return dispatch(map[string]func(*program.Program){
+		string(parsed.Methods["decimals"].ID): func(p *program.Program) {
+			p.Push(6).Push0().Op(vm.MSTORE).Return(0, 32)
+		},
+		string(parsed.Methods["balanceOf"].ID): func(p *program.Program) {
+			// balances are stored at the account's address
+			p.Push(4).Op(vm.CALLDATALOAD, vm.SLOAD).Push0().Op(vm.MSTORE).Return(0, 32)
+		},
+		string(parsed.Methods["allowance"].ID): func(p *program.Program) {
+			p.Push(4).Op(vm.CALLDATALOAD).Push0().Op(vm.MSTORE)
+			p.Push(36).Op(vm.CALLDATALOAD).Push(32).Op(vm.MSTORE)
+			p.Push(64).Push0().Op(vm.KECCAK256, vm.SLOAD).Push0().Op(vm.MSTORE).Return(0, 32)
+		},
+		string(parsed.Methods["approve"].ID): func(p *program.Program) {
+			// allowance[caller][spender] = amount
+			p.Op(vm.CALLER).Push0().Op(vm.MSTORE)
+			p.Push(4).Op(vm.CALLDATALOAD).Push(32).Op(vm.MSTORE)
+			p.Push(36).Op(vm.CALLDATALOAD).Push(64).Push0().Op(vm.KECCAK256, vm.SSTORE)
+			// emit Approval(caller, spender, amount)
+			p.Push(36).Op(vm.CALLDATALOAD).Push0().Op(vm.MSTORE)
+			p.Push(4).Op(vm.CALLDATALOAD, vm.CALLER).Push(parsed.Events["Approval"].ID).Push(32).Push0().Op(vm.LOG3)
+			p.Push(1).Push0().Op(vm.MSTORE).Return(0, 32)
+		},
+		string(parsed.Methods["transferFrom"].ID): func(p *program.Program) {
+			// transferFrom(from @4, to @36, amount @68)
+			// allowance[from][caller] -= amount
+			p.Push(4).Op(vm.CALLDATALOAD).Push0().Op(vm.MSTORE)
+			p.Op(vm.CALLER).Push(32).Op(vm.MSTORE)
+			p.Push(64).Push0().Op(vm.KECCAK256, vm.DUP1, vm.SLOAD)
+			p.Push(68).Op(vm.CALLDATALOAD, vm.DUP2, vm.DUP2, vm.GT, vm.ISZERO)
+			require(p, "insufficient allowance")
+			p.Op(vm.SWAP1, vm.SUB, vm.SWAP1, vm.SSTORE)
+			// balances[from] -= amount
+			p.Push(4).Op(vm.CALLDATALOAD, vm.DUP1, vm.SLOAD)
+			p.Push(68).Op(vm.CALLDATALOAD, vm.DUP2, vm.DUP2, vm.GT, vm.ISZERO)
+			require(p, "insufficient balance")
+			p.Op(vm.SWAP1, vm.SUB, vm.SWAP1, vm.SSTORE)
+			// balances[to] += amount
+			p.Push(36).Op(vm.CALLDATALOAD, vm.DUP1, vm.SLOAD)
+			p.Push(68).Op(vm.CALLDATALOAD, vm.ADD, vm.SWAP1, vm.SSTORE)
+			// emit Transfer(from, to, amount)
+			p.Push(68).Op(vm.CALLDATALOAD).Push0().Op(vm.MSTORE)
+			p.Push(36).Op(vm.CALLDATALOAD).Push(4).Op(vm.CALLDATALOAD).Push(parsed.Events["Transfer"].ID).Push(32).Push0().Op(vm.LOG3)
+			p.Push(1).Push0().Op(vm.MSTORE).Return(0, 32)
+		},
+		string(parsed.Methods["name"].ID): func(p *program.Program) {
+			returnString(p, testTokenName)
+		},
+		string(parsed.Methods["version"].ID): func(p *program.Program) {
+			returnString(p, testTokenVersion)
+		},
+		string(parsed.Methods["nonces"].ID): func(p *program.Program) {
+			// nonces[owner] is stored at keccak256(owner)
+			p.Push(4).Op(vm.CALLDATALOAD).Push0().Op(vm.MSTORE)
+			p.Push(32).Push0().Op(vm.KECCAK256, vm.SLOAD).Push0().Op(vm.MSTORE).Return(0, 32)
+		},
+		string(parsed.Methods["DOMAIN_SEPARATOR"].ID): func(p *program.Program) {
+			pushDomainSeparator(p)
+			p.Push0().Op(vm.MSTORE).Return(0, 32)
+		},
+		string(parsed.Methods["permit"].ID): func(p *program.Program) {
+			// permit(owner @4, spender @36, value @68, deadline @100, v @132, r @164, s @196)
+			p.Op(vm.TIMESTAMP).Push(100).Op(vm.CALLDATALOAD, vm.LT, vm.ISZERO)
+			require(p, "permit expired")
+
+			// Stack: nonce slot, nonce
+			p.Push(4).Op(vm.CALLDATALOAD).Push0().Op(vm.MSTORE)
+			p.Push(32).Push0().Op(vm.KECCAK256, vm.DUP1, vm.SLOAD)
+			// Stack: nonce slot, keccak256(typehash, owner, spender, value, nonce, deadline)
+			p.Push(crypto.Keccak256([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"))).Push0().Op(vm.MSTORE)
+			p.Push(4).Op(vm.CALLDATALOAD).Push(32).Op(vm.MSTORE)
+			p.Push(36).Op(vm.CALLDATALOAD).Push(64).Op(vm.MSTORE)
+			p.Push(68).Op(vm.CALLDATALOAD).Push(96).Op(vm.MSTORE)
+			p.Push(128).Op(vm.MSTORE)
+			p.Push(100).Op(vm.CALLDATALOAD).Push(160).Op(vm.MSTORE)
+			p.Push(192).Push0().Op(vm.KECCAK256)
+			// Stack: nonce slot, keccak256("\x19\x01", domain separator, struct hash)
+			pushDomainSeparator(p)
+			p.Push(0x19).Push0().Op(vm.MSTORE8)
+			p.Push(1).Push(1).Op(vm.MSTORE8)
+			p.Push(2).Op(vm.MSTORE)
+			p.Push(34).Op(vm.MSTORE)
+			p.Push(66).Push0().Op(vm.KECCAK256)
+			// require(ecrecover(digest, v, r, s) == owner)
+			p.Push0().Op(vm.MSTORE)
+			p.Push(132).Op(vm.CALLDATALOAD).Push(32).Op(vm.MSTORE)
+			p.Push(164).Op(vm.CALLDATALOAD).Push(64).Op(vm.MSTORE)
+			p.Push(196).Op(vm.CALLDATALOAD).Push(96).Op(vm.MSTORE)
+			p.Push0().Push(128).Op(vm.MSTORE)
+			p.StaticCall(nil, 1, 0, 128, 128, 32).Op(vm.POP)
+			p.Push(128).Op(vm.MLOAD).Push(4).Op(vm.CALLDATALOAD, vm.EQ)
+			require(p, "invalid signature")
+			// nonces[owner]++
+			p.Op(vm.DUP1, vm.SLOAD).Push(1).Op(vm.ADD, vm.SWAP1, vm.SSTORE)
+			// allowance[owner][spender] = value
+			p.Push(68).Op(vm.CALLDATALOAD)
+			p.Push(4).Op(vm.CALLDATALOAD).Push0().Op(vm.MSTORE)
+			p.Push(36).Op(vm.CALLDATALOAD).Push(32).Op(vm.MSTORE)
+			p.Push(64).Push0().Op(vm.KECCAK256, vm.SSTORE)
+			// emit Approval(owner, spender, value)
+			p.Push(68).Op(vm.CALLDATALOAD).Push0().Op(vm.MSTORE)
+			p.Push(36).Op(vm.CALLDATALOAD).Push(4).Op(vm.CALLDATALOAD).Push(parsed.Events["Approval"].ID).Push(32).Push0().Op(vm.LOG3, vm.STOP)
+		},
+	})
+}
+
+// pushDomainSeparator pushes the EIP-712 domain separator of the mock ERC20, computed
+// from the chain ID and the contract's own address. It uses memory [0, 160).
+func pushDomainSeparator(p *program.Program) {
+	p.Push(crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))).Push0().Op(vm.MSTORE)
+	p.Push(crypto.Keccak256([]byte(testTokenName))).Push(32).Op(vm.MSTORE)
+	p.Push(crypto.Keccak256([]byte(testTokenVersion))).Push(64).Op(vm.MSTORE)
+	p.Op(vm.CHAINID).Push(96).Op(vm.MSTORE)
+	p.Op(vm.ADDRESS).Push(128).Op(vm.MSTORE)
+	p.Push(160).Push0().Op(vm.KECCAK256)
+}
+
+// returnString returns s ABI encoded.
+func returnString(p *program.Program, s string) {
+	stringType, err := abi.NewType("string", "", nil)
+	if err != nil {
+		panic(err)
+	}
+	encoded, err := abi.Arguments{{Type: stringType}}.Pack(s)
+	if err != nil {
+		panic(err)
+	}
+	p.Mstore(encoded, 0).Return(0, len(encoded))
+}
+
+// require pops a condition and reverts with Error(reason) if it is zero.
+func require(p *program.Program, reason string) {
+	stringType, err := abi.NewType("string", "", nil)
+	if err != nil {
+		panic(err)
+	}
+	encoded, err := abi.Arguments{{Type: stringType}}.Pack(reason)
+	if err != nil {
+		panic(err)
+	}
+	data := append(crypto.Keccak256([]byte("Error(string)"))[:4], encoded...)
+	revert := program.New().Mstore(data, 0).Push(len(data)).Push0().Op(vm.REVERT).Bytes()
+	pushLabel(p, p.Size()+4+len(revert))
+	p.Op(vm.JUMPI).Append(revert).Op(vm.JUMPDEST)
+}
+
+// mockMMarket assembles the contract described by mockMMarketABI.
+func mockMMarket(parsed abi.ABI) []byte {
+	return dispatch(map[string]func(*program.Program){
+		string(parsed.Methods["settle"].ID): func(p *program.Program) {
+			// require(expiry <= block.timestamp)
+			p.Push(4).Op(vm.CALLDATALOAD, vm.TIMESTAMP, vm.LT, vm.ISZERO)
+			require(p, "not expired")
+			// settled[expiry] = true
+			p.Push(1).Push(4).Op(vm.CALLDATALOAD, vm.SSTORE, vm.STOP)
+		},
+		string(parsed.Methods["settled"].ID): func(p *program.Program) {
+			p.Push(4).Op(vm.CALLDATALOAD, vm.SLOAD).Push0().Op(vm.MSTORE).Return(0, 32)
+		},
+		string(parsed.Methods["deposit"].ID): func(p *program.Program) {
+			// asset.transferFrom(caller, this, amount), passing on its revert
+			transferFrom := crypto.Keccak256([]byte("transferFrom(address,address,uint256)"))[:4]
+			p.Push(transferFrom).Push(224).Op(vm.SHL).Push0().Op(vm.MSTORE)
+			p.Op(vm.CALLER).Push(4).Op(vm.MSTORE)
+			p.Op(vm.ADDRESS).Push(36).Op(vm.MSTORE)
+			p.Push(36).Op(vm.CALLDATALOAD).Push(68).Op(vm.MSTORE)
+			p.Push0().Push0().Push(100).Push0().Push0().Push(4).Op(vm.CALLDATALOAD, vm.GAS, vm.CALL)
+			revert := program.New().Op(vm.RETURNDATASIZE).Push0().Push0().Op(vm.RETURNDATACOPY, vm.RETURNDATASIZE).Push0().Op(vm.REVERT).Bytes()
+			pushLabel(p, p.Size()+4+len(revert))
+			p.Op(vm.JUMPI).Append(revert).Op(vm.JUMPDEST, vm.STOP)
+		},
+	})
+}
+
+// dispatch assembles runtime code that jumps to the body of the method whose 4 byte
+// selector starts the calldata, and reverts on unknown selectors. Bodies are
+// appended in place, so program offsets within them are absolute, and must end
+// execution.
+func dispatch(bodies map[string]func(*program.Program)) []byte {
+	selectors := make([]string, 0, len(bodies))
+	for selector := range bodies {
+		selectors = append(selectors, selector)
+	}
+	sort.Strings(selectors)
+
+	p := program.New()
+	p.Push0().Op(vm.CALLDATALOAD).Push(224).Op(vm.SHR)
+	labels := make([]int, len(selectors))
+	for i, selector := range selectors {
+		p.Op(vm.DUP1).Push([]byte(selector)).Op(vm.EQ)
+		labels[i] = p.Size()
+		pushLabel(p, 0)
+		p.Op(vm.JUMPI)
+	}
+	p.Push0().Push0().Op(vm.REVERT)
+
+	for i, selector := range selectors {
+		binary.BigEndian.PutUint16(p.Bytes()[labels[i]+1:], uint16(p.Size()))
+		p.Op(vm.JUMPDEST)
+		bodies[selector](p)
+	}
+	return p.Bytes()
+}
+
+// pushLabel pushes the code offset loc with a fixed size PUSH2, so that the size of
+// the code does not depend on it.
+func pushLabel(p *program.Program, loc int) {
+	p.Op(vm.PUSH2).Append(binary.BigEndian.AppendUint16(nil, uint16(loc)))
+}
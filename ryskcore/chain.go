@@ -5,12 +5,11 @@ import (
 	"crypto/ecdsa"
 	"fmt" // Added for error wrapping
 	"math/big"
-	"strings"
 
+	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	crypto "github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 const (
@@ -21,10 +20,27 @@ const (
 	CHAIN_ID_MONAD_TESTNET int = 10143
 )
 
+// Addresses are the protocol contracts on a chain.
 type Addresses struct {
-	MarginPool  common.Address
-	MMarket     common.Address
-	StrikeAsset common.Address
+	MarginPool  common.Address `json:"marginPool" yaml:"marginPool"`
+	MMarket     common.Address `json:"mmarket" yaml:"mmarket"`
+	StrikeAsset common.Address `json:"strikeAsset" yaml:"strikeAsset"`
+}
+
+// ADDRESSES holds the protocol addresses of each chain in CHAINS. It is updated when
+// chains are registered with LoadChains, but not when CHAINS is modified directly.
+//
+// Deprecated: use GetAddresses or CHAINS.
+var ADDRESSES = map[int]Addresses{}
+
+// Backend is what an Account needs from a node to send transactions and follow them.
+// ethclient.Client and the client of go-ethereum's simulated backend both satisfy it.
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	ethereum.ChainIDReader
+	ethereum.TransactionReader
+	ethereum.PendingContractCaller
 }
 
 type Account struct {
@@ -32,82 +48,89 @@ type Account struct {
 	Private *ecdsa.PrivateKey
 }
 
-var ADDRESSES = map[int]Addresses{
-	CHAIN_ID_BASE_SEPOLIA: {
-		MarginPool:  common.HexToAddress("0xf0ac2edd8eb37b4ac75a2ed02eeae3a5c6bb8e8e"),
-		MMarket:     common.HexToAddress("0x6d23217cb228f3779f6506b44b1abaa3edf6fc58"),
-		StrikeAsset: common.HexToAddress("0x98d56648c9b7f3cb49531f4135115b5000ab1733"),
-	},
-	CHAIN_ID_MONAD_TESTNET: {
-		MarginPool:  common.HexToAddress("0x968d1f9dcda00df7be0bbc0acf3761853d06eb33"),
-		MMarket:     common.HexToAddress("0x0d71dbdb11c35c72fa4a33beec00d5eb1fbcf1c8"),
-		StrikeAsset: common.HexToAddress("0xf817257fed379853cde0fa4f97ab987181b1e5ea"),
-	},
-	CHAIN_ID_HYPE_TESTNET: {
-		MarginPool: common.HexToAddress("0xB586aBB1b69611284A855BAECE6707bf353C035b"),
-		MMarket:     common.HexToAddress("0x4bB4dDf8F4588780fe0cD31593bd8f5719eBfefd"),
-		StrikeAsset: common.HexToAddress("0x69E37eCb273a23B8864A5f39520Bf97c6768b65C"),
-	},
-	CHAIN_ID_BASE: {
-		MarginPool:  common.HexToAddress("0x6EBec8b078464B5d59eCc1c23F7F37e65b75e61f"),
-		MMarket:     common.HexToAddress("0x3D9CB5D2Fa4600bF8d75fB59Fe01Db765dCced15"),
-		StrikeAsset: common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"),
-	},
-}
-
 // NewAccountFromPrivateKey creates an Account object from a hex-encoded private key.
+// Surrounding whitespace and a 0x prefix are accepted.
 func NewAccountFromPrivateKey(pk string) (account Account, err error) {
-	account = Account{}
-	account.Private, err = crypto.HexToECDSA(strings.TrimPrefix(pk, "0x")) // hex w/o 0x
-	if err != nil {
-		return account, err
-	}
-
-	account.Public = crypto.PubkeyToAddress(account.Private.PublicKey)
-	return account, nil
-
+	return NewAccountFromKeySource(KeyFromString(pk))
 }
 
-func (a *Account) newTransactionOpts(ctx context.Context, chainID int, c ethclient.Client) (*bind.TransactOpts, error) {
-	nonce, err := c.PendingNonceAt(ctx, a.Public)
-	if err != nil {
+// newTransactionOpts returns options that sign for chainID, after checking that the
+// node serves that chain.
+func (a *Account) newTransactionOpts(ctx context.Context, chainID int, c Backend, txOpts TxOptions) (*bind.TransactOpts, error) {
+	if err := checkChainID(ctx, c, chainID); err != nil {
 		return nil, err
 	}
-	gasprice, err := c.SuggestGasPrice(ctx)
+	var nonce uint64
+	var err error
+	if txOpts.Nonces != nil {
+		nonce, err = txOpts.Nonces.Next(ctx, chainID, a.Public, c)
+	} else {
+		nonce, err = c.PendingNonceAt(ctx, a.Public)
+	}
 	if err != nil {
 		return nil, err
 	}
 	opts, err := bind.NewKeyedTransactorWithChainID(a.Private, new(big.Int).SetInt64(int64(chainID)))
+	if err == nil {
+		err = txOpts.setFees(ctx, opts, c)
+	}
 	if err != nil {
+		if txOpts.Nonces != nil {
+			txOpts.Nonces.Release(chainID, a.Public, nonce)
+		}
 		return nil, err
 	}
-	opts.Nonce = big.NewInt(int64(nonce))
-	opts.GasPrice = gasprice
+	opts.Context = ctx
+	opts.Nonce = new(big.Int).SetUint64(nonce)
 	return opts, nil
 }
 
 // Approve allows the MMarket contract to spend a certain amount of the StrikeAsset from the account.
-func (a *Account) Approve(ctx context.Context, chainID int, client ethclient.Client, amount *big.Int) (txHash string, err error) {
-	opts, err := a.newTransactionOpts(ctx, chainID, client)
+func (a *Account) Approve(ctx context.Context, chainID int, client Backend, amount *big.Int, txOpts TxOptions) (*TxResult, error) {
+	addresses, err := GetAddresses(chainID)
 	if err != nil {
-		// Propagate the error from newTransactionOpts correctly
-		return "", fmt.Errorf("failed to create transaction options: %w", err)
+		return nil, err
 	}
+	if addresses.StrikeAsset == ZeroAddress || addresses.MMarket == ZeroAddress {
+		return nil, fmt.Errorf("chain %d has no strike asset or MMarket configured", chainID)
+	}
+	return a.ApproveSpender(ctx, chainID, client, addresses.StrikeAsset, addresses.MMarket, amount, txOpts)
+}
 
-	erc20, err := NewIERC20(ADDRESSES[chainID].StrikeAsset, &client)
+// ApproveSpender sets the allowance of spender over the account's token to amount.
+// A reverted approval returns its result together with a *RevertError.
+func (a *Account) ApproveSpender(ctx context.Context, chainID int, client Backend, token, spender common.Address, amount *big.Int, txOpts TxOptions) (*TxResult, error) {
+	erc20, err := NewIERC20(token, client)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	tx, err := erc20.Approve(opts, ADDRESSES[chainID].MMarket, amount)
+	tx, err := a.transact(ctx, chainID, client, txOpts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return erc20.Approve(opts, spender, amount)
+	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	rx, err := bind.WaitMined(ctx, &client, tx)
+	return a.complete(ctx, client, tx, txOpts)
+}
+
+// EnsureAllowance approves target for spender over the account's token, but only if
+// the current allowance is below min. It returns a nil result if nothing was sent.
+func (a *Account) EnsureAllowance(ctx context.Context, chainID int, client Backend, token, spender common.Address, min, target *big.Int, txOpts TxOptions) (*TxResult, error) {
+	if target.Cmp(min) < 0 {
+		return nil, fmt.Errorf("target allowance %s is below the minimum %s", target, min)
+	}
+	erc20, err := NewIERC20Caller(token, client)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-
-	return rx.TxHash.Hex(), nil
+	allowance, err := erc20.Allowance(&bind.CallOpts{Context: ctx}, a.Public, spender)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read allowance: %w", err)
+	}
+	if allowance.Cmp(min) >= 0 {
+		return nil, nil
+	}
+	return a.ApproveSpender(ctx, chainID, client, token, spender, target, txOpts)
 }
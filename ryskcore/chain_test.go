@@ -0,0 +1,194 @@
+package ryskcore
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestApproveSpender(t *testing.T) {
+	t.Parallel()
+	chain := newTestChain(t)
+	ctx := context.Background()
+
+	result, err := chain.account.ApproveSpender(ctx, testChainID, chain.client, chain.token, chain.mmarket, big.NewInt(1000), TxOptions{})
+	if err != nil {
+		t.Fatalf("ApproveSpender: %v", err)
+	}
+	if result.Status != TxStatusSuccess || result.BlockNumber == nil || result.GasUsed == 0 {
+		t.Errorf("ApproveSpender result = %+v, want a mined success", result)
+	}
+	if got := chain.allowance(t); got.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("allowance = %s, want 1000", got)
+	}
+
+	status, err := TxStatus(ctx, chain.client, result.Hash)
+	if err != nil {
+		t.Fatalf("TxStatus: %v", err)
+	}
+	if status.Status != TxStatusSuccess || status.BlockNumber.Cmp(result.BlockNumber) != 0 {
+		t.Errorf("TxStatus = %+v, want %+v", status, result)
+	}
+}
+
+func TestEnsureAllowance(t *testing.T) {
+	t.Parallel()
+	chain := newTestChain(t)
+	ctx := context.Background()
+	min, target := big.NewInt(500), big.NewInt(1000)
+
+	result, err := chain.account.EnsureAllowance(ctx, testChainID, chain.client, chain.token, chain.mmarket, min, target, TxOptions{})
+	if err != nil {
+		t.Fatalf("EnsureAllowance: %v", err)
+	}
+	if result == nil || result.Status != TxStatusSuccess {
+		t.Fatalf("EnsureAllowance result = %+v, want an approval", result)
+	}
+	if got := chain.allowance(t); got.Cmp(target) != 0 {
+		t.Errorf("allowance = %s, want %s", got, target)
+	}
+
+	result, err = chain.account.EnsureAllowance(ctx, testChainID, chain.client, chain.token, chain.mmarket, min, target, TxOptions{})
+	if err != nil || result != nil {
+		t.Errorf("EnsureAllowance with enough allowance = %+v, %v, want nothing sent", result, err)
+	}
+
+	if _, err := chain.account.EnsureAllowance(ctx, testChainID, chain.client, chain.token, chain.mmarket, target, min, TxOptions{}); err == nil {
+		t.Error("EnsureAllowance with a target below the minimum succeeded")
+	}
+}
+
+func TestApproveDryRun(t *testing.T) {
+	t.Parallel()
+	chain := newTestChain(t)
+
+	result, err := chain.account.ApproveSpender(context.Background(), testChainID, chain.client, chain.token, chain.mmarket, big.NewInt(1000), TxOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("ApproveSpender: %v", err)
+	}
+	sim := result.Simulation
+	if result.Status != TxStatusSimulated || sim == nil {
+		t.Fatalf("ApproveSpender result = %+v, want a simulation", result)
+	}
+	if sim.Method != "approve(address,uint256)" || sim.Gas == 0 || sim.MaxCost == nil {
+		t.Errorf("Simulation = %+v, want a decoded approve with gas and cost", sim)
+	}
+	if len(sim.Return) != 1 || sim.Return[0] != true {
+		t.Errorf("Simulation.Return = %v, want [true]", sim.Return)
+	}
+	if got := chain.allowance(t); got.Sign() != 0 {
+		t.Errorf("allowance after a dry run = %s, want 0", got)
+	}
+}
+
+func TestNewTransactionOptsChecksChainID(t *testing.T) {
+	t.Parallel()
+	chain := newTestChain(t)
+
+	_, err := chain.account.newTransactionOpts(context.Background(), CHAIN_ID_BASE, chain.client, TxOptions{})
+	if _, ok := err.(*ChainIDError); !ok {
+		t.Errorf("newTransactionOpts for the wrong chain = %v, want a *ChainIDError", err)
+	}
+}
+
+func TestSpenderPullsApprovedFunds(t *testing.T) {
+	t.Parallel()
+	chain := newTestChain(t)
+	ctx := context.Background()
+
+	_, err := chain.transactMMarket(ctx, TxOptions{}, "deposit", chain.token, big.NewInt(600))
+	checkRevert(t, err, "insufficient allowance")
+
+	if _, err := chain.account.ApproveSpender(ctx, testChainID, chain.client, chain.token, chain.mmarket, big.NewInt(1000), TxOptions{}); err != nil {
+		t.Fatalf("ApproveSpender: %v", err)
+	}
+	result, err := chain.transactMMarket(ctx, TxOptions{}, "deposit", chain.token, big.NewInt(600))
+	if err != nil || result.Status != TxStatusSuccess {
+		t.Fatalf("deposit = %+v, %v, want a mined success", result, err)
+	}
+	if got := chain.allowance(t); got.Cmp(big.NewInt(400)) != 0 {
+		t.Errorf("allowance = %s, want 400 left", got)
+	}
+	for _, holder := range []struct {
+		address common.Address
+		want    *big.Int
+	}{
+		{chain.account.Public, new(big.Int).Sub(testTokenBalance, big.NewInt(600))},
+		{chain.mmarket, big.NewInt(600)},
+	} {
+		if got := chain.balance(t, holder.address); got.Cmp(holder.want) != 0 {
+			t.Errorf("balance of %s = %s, want %s", holder.address.Hex(), got, holder.want)
+		}
+	}
+
+	_, err = chain.transactMMarket(ctx, TxOptions{}, "deposit", chain.token, big.NewInt(600))
+	checkRevert(t, err, "insufficient allowance")
+}
+
+func TestSettleAfterExpiry(t *testing.T) {
+	t.Parallel()
+	chain := newTestChain(t)
+	ctx := context.Background()
+	chain.pauseMining()
+	latest, err := chain.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiry := new(big.Int).SetUint64(latest.Time + 60)
+
+	_, err = chain.transactMMarket(ctx, TxOptions{}, "settle", expiry)
+	checkRevert(t, err, "not expired")
+	result, err := chain.transactMMarket(ctx, TxOptions{DryRun: true}, "settle", expiry)
+	if reverted := checkRevert(t, err, "not expired"); result != reverted || result.Simulation == nil {
+		t.Errorf("settle dry run = %+v, want the reverted simulation", result)
+	}
+
+	if err := chain.backend.AdjustTime(time.Hour); err != nil {
+		t.Fatalf("AdjustTime: %v", err)
+	}
+	sent, err := chain.transactMMarket(ctx, TxOptions{NoWait: true}, "settle", expiry)
+	if err != nil {
+		t.Fatalf("settle: %v", err)
+	}
+	chain.backend.Commit()
+	if result, err := TxStatus(ctx, chain.client, sent.Hash); err != nil || result.Status != TxStatusSuccess {
+		t.Fatalf("TxStatus = %+v, %v, want a mined success", result, err)
+	}
+	var settled []any
+	mmarket := bind.NewBoundContract(chain.mmarket, chain.mmarketABI, chain.client, chain.client, chain.client)
+	if err := mmarket.Call(&bind.CallOpts{}, &settled, "settled", expiry); err != nil || len(settled) != 1 || settled[0] != true {
+		t.Errorf("settled(%s) = %v, %v, want true", expiry, settled, err)
+	}
+}
+
+// allowance reads the allowance of the mock MMarket over the account's mock tokens.
+func (c *testChain) allowance(t *testing.T) *big.Int {
+	t.Helper()
+	erc20, err := NewIERC20Caller(c.token, c.client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowance, err := erc20.Allowance(&bind.CallOpts{}, c.account.Public, c.mmarket)
+	if err != nil {
+		t.Fatalf("Allowance: %v", err)
+	}
+	return allowance
+}
+
+// balance reads holder's balance of the mock ERC20.
+func (c *testChain) balance(t *testing.T, holder common.Address) *big.Int {
+	t.Helper()
+	erc20, err := NewIERC20Caller(c.token, c.client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance, err := erc20.BalanceOf(&bind.CallOpts{}, holder)
+	if err != nil {
+		t.Fatalf("BalanceOf: %v", err)
+	}
+	return balance
+}
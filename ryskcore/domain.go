@@ -0,0 +1,157 @@
+package ryskcore
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/goccy/go-json"
+)
+
+// Domain holds the chain independent fields of an EIP-712 domain.
+// Empty fields are left out of both the domain type and its hash.
+type Domain struct {
+	Name              string `json:"name,omitempty"`
+	Version           string `json:"version,omitempty"`
+	VerifyingContract string `json:"verifyingContract,omitempty"`
+	Salt              string `json:"salt,omitempty"`
+}
+
+// ChainDomains holds the EIP-712 domains of a chain. Messages overrides
+// Default for individual primary types, e.g. "Quote" or "Transfer".
+type ChainDomains struct {
+	Default  Domain            `json:"default"`
+	Messages map[string]Domain `json:"messages,omitempty"`
+}
+
+// DEFAULT_DOMAIN is the domain used by the protocol unless DOMAINS says otherwise.
+var DEFAULT_DOMAIN = Domain{
+	Name:              "rysk",
+	Version:           "0.0.0",
+	VerifyingContract: ZeroAddress.String(),
+}
+
+// DOMAINS holds per chain overrides of DEFAULT_DOMAIN.
+var DOMAINS = map[int]ChainDomains{}
+
+// DomainFor returns the domain used to sign primaryType messages on chainID.
+// Fields that are empty in an override are inherited from the chain default,
+// then from DEFAULT_DOMAIN.
+func DomainFor(chainID int, primaryType string) Domain {
+	d := DEFAULT_DOMAIN
+	if cd, ok := DOMAINS[chainID]; ok {
+		d = d.merge(cd.Default)
+		if md, ok := cd.Messages[primaryType]; ok {
+			d = d.merge(md)
+		}
+	}
+	return d
+}
+
+// SetDomain merges d into the domain of chainID. An empty primaryType updates the
+// chain default, otherwise only messages of that primary type are affected.
+func SetDomain(chainID int, primaryType string, d Domain) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	cd := DOMAINS[chainID]
+	if primaryType == "" {
+		cd.Default = cd.Default.merge(d)
+	} else {
+		if cd.Messages == nil {
+			cd.Messages = map[string]Domain{}
+		}
+		cd.Messages[primaryType] = cd.Messages[primaryType].merge(d)
+	}
+	DOMAINS[chainID] = cd
+	return nil
+}
+
+// LoadDomains merges the domains in the JSON file at path into DOMAINS.
+// The file maps chain ids to ChainDomains, e.g.
+//
+//	{"8453": {"default": {"version": "1.0.0"}, "messages": {"Transfer": {"name": "rysk-transfer"}}}}
+func LoadDomains(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var domains map[int]ChainDomains
+	if err := json.Unmarshal(data, &domains); err != nil {
+		return fmt.Errorf("invalid domain config %s: %w", path, err)
+	}
+	for chainID, cd := range domains {
+		if err := SetDomain(chainID, "", cd.Default); err != nil {
+			return fmt.Errorf("chain %d: %w", chainID, err)
+		}
+		for primaryType, d := range cd.Messages {
+			if err := SetDomain(chainID, primaryType, d); err != nil {
+				return fmt.Errorf("chain %d %s: %w", chainID, primaryType, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the format of the verifying contract and salt.
+func (d Domain) Validate() error {
+	if d.VerifyingContract != "" && !common.IsHexAddress(d.VerifyingContract) {
+		return fmt.Errorf("invalid verifying contract %q", d.VerifyingContract)
+	}
+	if d.Salt != "" {
+		salt, err := hexutil.Decode(d.Salt)
+		if err != nil || len(salt) != common.HashLength {
+			return fmt.Errorf("invalid salt %q, expected 32 bytes of 0x prefixed hex", d.Salt)
+		}
+	}
+	return nil
+}
+
+func (d Domain) merge(o Domain) Domain {
+	if o.Name != "" {
+		d.Name = o.Name
+	}
+	if o.Version != "" {
+		d.Version = o.Version
+	}
+	if o.VerifyingContract != "" {
+		d.VerifyingContract = o.VerifyingContract
+	}
+	if o.Salt != "" {
+		d.Salt = o.Salt
+	}
+	return d
+}
+
+// types returns the EIP712Domain type matching the fields set in d, in the order
+// mandated by EIP-712.
+func (d Domain) types() []apitypes.Type {
+	var t []apitypes.Type
+	if d.Name != "" {
+		t = append(t, apitypes.Type{Name: "name", Type: "string"})
+	}
+	if d.Version != "" {
+		t = append(t, apitypes.Type{Name: "version", Type: "string"})
+	}
+	t = append(t, apitypes.Type{Name: "chainId", Type: "uint256"})
+	if d.VerifyingContract != "" {
+		t = append(t, apitypes.Type{Name: "verifyingContract", Type: "address"})
+	}
+	if d.Salt != "" {
+		t = append(t, apitypes.Type{Name: "salt", Type: "bytes32"})
+	}
+	return t
+}
+
+func (d Domain) typedDataDomain(chainId int64) *apitypes.TypedDataDomain {
+	return &apitypes.TypedDataDomain{
+		Name:              d.Name,
+		Version:           d.Version,
+		ChainId:           math.NewHexOrDecimal256(chainId),
+		VerifyingContract: d.VerifyingContract,
+		Salt:              d.Salt,
+	}
+}
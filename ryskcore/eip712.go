@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	crypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	"github.com/goccy/go-json"
@@ -117,12 +117,14 @@ func EncodeTypedData(typedData *apitypes.TypedData) (common.Hash, error) {
 	return hash, err
 }
 
-// Signs msg with EIP712 signing scheme
+// Signs msg with EIP712 signing scheme.
+// The hex private key may have surrounding whitespace and a 0x prefix.
 func Sign(message []byte, privateKey string) (string, error) {
-	privateKeyEcdsa, err := crypto.HexToECDSA(privateKey)
+	privateKeyEcdsa, err := parsePrivateKey([]byte(privateKey))
 	if err != nil {
 		return "", err
 	}
+	defer clear(privateKeyEcdsa.D.Bits())
 	sigBytes, err := signTypedData(message, privateKeyEcdsa)
 	if err != nil {
 		return "", err
@@ -131,6 +133,56 @@ func Sign(message []byte, privateKey string) (string, error) {
 	return signature, nil
 }
 
+// Sign signs message with the account's key, like the package level Sign but
+// without parsing the private key on every call.
+func (a *Account) Sign(message []byte) (string, error) {
+	sigBytes, err := signTypedData(message, a.Private)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("0x%s", common.Bytes2Hex(sigBytes)), nil
+}
+
+// RecoverSigner returns the address that produced signature over message.
+// It accepts signatures with a recovery id of 0/1 as well as 27/28.
+func RecoverSigner(message []byte, signature string) (common.Address, error) {
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid signature %q: %w", signature, err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid signature length %d, expected %d", len(sig), crypto.SignatureLength)
+	}
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	pub, err := crypto.SigToPub(message, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// NormalizeSignature returns signature in the form Sign produces: 0x prefixed lower
+// case hex with a recovery id of 27/28. Wallets may return 0/1 instead.
+func NormalizeSignature(signature string) (string, error) {
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return "", fmt.Errorf("invalid signature %q: %w", signature, err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return "", fmt.Errorf("invalid signature length %d, expected %d", len(sig), crypto.SignatureLength)
+	}
+	switch sig[64] {
+	case 0, 1:
+		sig[64] += 27
+	case 27, 28:
+	default:
+		return "", fmt.Errorf("invalid signature recovery id %d, expected 0, 1, 27 or 28", sig[64])
+	}
+	return hexutil.Encode(sig), nil
+}
+
 func signTypedData(message []byte, privateKey *ecdsa.PrivateKey) (sig []byte, err error) {
 	sig, err = crypto.Sign(message, privateKey)
 	if err != nil {
@@ -140,20 +192,22 @@ func signTypedData(message []byte, privateKey *ecdsa.PrivateKey) (sig []byte, er
 	return
 }
 
-func createEIP712Domain(chainId int64) *apitypes.TypedDataDomain {
-	return &apitypes.TypedDataDomain{
-		Name:              "rysk",
-		Version:           "0.0.0",
-		ChainId:           math.NewHexOrDecimal256(chainId),
-		VerifyingContract: ZeroAddress.String(),
-	}
+func createEIP712Domain(chainId int64, msgType string) (*apitypes.TypedDataDomain, []apitypes.Type) {
+	d := DomainFor(int(chainId), msgType)
+	return d.typedDataDomain(chainId), d.types()
 }
 
 func createEIP712TypedData(chainId int64, msgType string, msg map[string]interface{}) *apitypes.TypedData {
+	domain, domainTypes := createEIP712Domain(chainId, msgType)
+	types := apitypes.Types{}
+	for name, fields := range *EIP712_TYPES {
+		types[name] = fields
+	}
+	types["EIP712Domain"] = domainTypes
 	return &apitypes.TypedData{
-		Types:       *EIP712_TYPES,
+		Types:       types,
 		PrimaryType: msgType,
-		Domain:      *createEIP712Domain(chainId),
+		Domain:      *domain,
 		Message:     msg,
 	}
 }
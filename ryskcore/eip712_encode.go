@@ -0,0 +1,132 @@
+package ryskcore
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	crypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// QuoteHash and TransferHash compute the same digests as CreateQuoteMessage and
+// CreateTransferMessage without going through apitypes. Fields are ABI encoded
+// straight from the struct, type hashes are computed once, and domain separators
+// are cached per chain, message type and domain.
+
+var (
+	quoteTypeHash    = typeHash("Quote")
+	transferTypeHash = typeHash("Transfer")
+
+	domainSeparators sync.Map // domainKey -> common.Hash
+)
+
+type domainKey struct {
+	chainID int
+	domain  Domain
+}
+
+func typeHash(primaryType string) common.Hash {
+	typedData := apitypes.TypedData{Types: *EIP712_TYPES}
+	return common.BytesToHash(typedData.TypeHash(primaryType))
+}
+
+// DomainSeparator returns the EIP-712 domain separator used to sign primaryType
+// messages on chainID.
+func DomainSeparator(chainID int, primaryType string) (common.Hash, error) {
+	key := domainKey{chainID: chainID, domain: DomainFor(chainID, primaryType)}
+	if sep, ok := domainSeparators.Load(key); ok {
+		return sep.(common.Hash), nil
+	}
+	typedData := apitypes.TypedData{
+		Types:  apitypes.Types{"EIP712Domain": key.domain.types()},
+		Domain: *key.domain.typedDataDomain(int64(chainID)),
+	}
+	sep, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	domainSeparators.Store(key, common.BytesToHash(sep))
+	return common.BytesToHash(sep), nil
+}
+
+// QuoteHash returns the EIP-712 digest of q.
+func QuoteHash(q Quote) (common.Hash, error) {
+	var enc [12 * 32]byte
+	copy(enc[0:], quoteTypeHash[:])
+	copy(enc[32:], crypto.Keccak256([]byte(q.AssetAddress)))
+	if err := encodeInt(enc[64:96], "chainId", int64(q.ChainID)); err != nil {
+		return common.Hash{}, err
+	}
+	encodeBool(enc[96:128], q.IsPut)
+	encodeUint256(enc[128:160], q.Strike)
+	if err := encodeInt(enc[160:192], "expiry", q.Expiry); err != nil {
+		return common.Hash{}, err
+	}
+	if err := encodeAddress(enc[192:224], "maker", q.Maker); err != nil {
+		return common.Hash{}, err
+	}
+	copy(enc[224:], crypto.Keccak256([]byte(q.Nonce)))
+	encodeUint256(enc[256:288], q.Price)
+	encodeUint256(enc[288:320], q.Quantity)
+	encodeBool(enc[320:352], q.IsTakerBuy)
+	if err := encodeInt(enc[352:384], "validUntil", q.ValidUntil); err != nil {
+		return common.Hash{}, err
+	}
+	return typedDataHash(q.ChainID, "Quote", enc[:])
+}
+
+// TransferHash returns the EIP-712 digest of t.
+func TransferHash(t Transfer) (common.Hash, error) {
+	var enc [6 * 32]byte
+	copy(enc[0:], transferTypeHash[:])
+	if err := encodeAddress(enc[32:64], "asset", t.Asset); err != nil {
+		return common.Hash{}, err
+	}
+	if err := encodeInt(enc[64:96], "chainId", int64(t.ChainID)); err != nil {
+		return common.Hash{}, err
+	}
+	encodeUint256(enc[96:128], t.Amount)
+	encodeBool(enc[128:160], t.IsDeposit)
+	copy(enc[160:], crypto.Keccak256([]byte(t.Nonce)))
+	return typedDataHash(t.ChainID, "Transfer", enc[:])
+}
+
+func typedDataHash(chainID int, primaryType string, encodedData []byte) (common.Hash, error) {
+	sep, err := DomainSeparator(chainID, primaryType)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var raw [2 + 2*32]byte
+	raw[0], raw[1] = 0x19, 0x01
+	copy(raw[2:], sep[:])
+	copy(raw[34:], crypto.Keccak256(encodedData))
+	return crypto.Keccak256Hash(raw[:]), nil
+}
+
+func encodeBool(dst []byte, v bool) {
+	if v {
+		dst[31] = 1
+	}
+}
+
+func encodeInt(dst []byte, field string, v int64) error {
+	if v < 0 {
+		return fmt.Errorf("invalid negative value %d for %s", v, field)
+	}
+	big.NewInt(v).FillBytes(dst)
+	return nil
+}
+
+func encodeUint256(dst []byte, v Uint256) {
+	copy(dst, v.b[:])
+}
+
+func encodeAddress(dst []byte, field string, v string) error {
+	if !common.IsHexAddress(v) {
+		return fmt.Errorf("invalid %s address %q", field, v)
+	}
+	copy(dst[12:], common.HexToAddress(v).Bytes())
+	return nil
+}
@@ -0,0 +1,220 @@
+package ryskcore
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	crypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+var testQuote = Quote{
+	AssetAddress: "0xb67bfa7b488df4f2efa874f4e59242e9130ae61f",
+	ChainID:      CHAIN_ID_BASE,
+	Expiry:       1750060800,
+	IsPut:        true,
+	IsTakerBuy:   false,
+	Maker:        "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23",
+	Nonce:        "1718900000123",
+	Price:        MustParseUint256("1250000000000000000"),
+	Quantity:     MustParseUint256("0x0de0b6b3a7640000"),
+	Strike:       MustParseUint256("3000000000000000000000"),
+	ValidUntil:   1749000000,
+}
+
+var testTransfer = Transfer{
+	Asset:     "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+	ChainID:   CHAIN_ID_BASE,
+	Amount:    Uint256FromUint64(1000000),
+	IsDeposit: true,
+	Nonce:     "42",
+}
+
+func TestQuoteHashMatchesTypedData(t *testing.T) {
+	call := testQuote
+	call.IsPut = false
+	call.IsTakerBuy = true
+	zero := testQuote
+	zero.Price, zero.Quantity, zero.Strike, zero.Expiry, zero.ValidUntil = Uint256{}, Uint256{}, Uint256{}, 0, 0
+
+	for name, q := range map[string]Quote{"put": testQuote, "call": call, "zero": zero} {
+		want, _, err := CreateQuoteMessage(q)
+		if err != nil {
+			t.Fatalf("%s: CreateQuoteMessage: %v", name, err)
+		}
+		got, err := QuoteHash(q)
+		if err != nil {
+			t.Fatalf("%s: QuoteHash: %v", name, err)
+		}
+		if !bytes.Equal(got.Bytes(), want) {
+			t.Errorf("%s: QuoteHash = %x, want %x", name, got, want)
+		}
+	}
+}
+
+func TestTransferHashMatchesTypedData(t *testing.T) {
+	withdraw := testTransfer
+	withdraw.IsDeposit = false
+	withdraw.Amount = MustParseUint256("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+
+	for name, tr := range map[string]Transfer{"deposit": testTransfer, "withdraw": withdraw} {
+		want, _, err := CreateTransferMessage(tr)
+		if err != nil {
+			t.Fatalf("%s: CreateTransferMessage: %v", name, err)
+		}
+		got, err := TransferHash(tr)
+		if err != nil {
+			t.Fatalf("%s: TransferHash: %v", name, err)
+		}
+		if !bytes.Equal(got.Bytes(), want) {
+			t.Errorf("%s: TransferHash = %x, want %x", name, got, want)
+		}
+	}
+}
+
+func TestHashMatchesTypedDataWithCustomDomain(t *testing.T) {
+	saved := DOMAINS
+	t.Cleanup(func() { DOMAINS = saved })
+	DOMAINS = map[int]ChainDomains{}
+
+	chainID := 31337
+	if err := SetDomain(chainID, "", Domain{Version: "1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	err := SetDomain(chainID, "Transfer", Domain{
+		Name:              "rysk-transfer",
+		VerifyingContract: "0x6EBec8b078464B5d59eCc1c23F7F37e65b75e61f",
+		Salt:              "0x0000000000000000000000000000000000000000000000000000000000000001",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := testQuote
+	q.ChainID = chainID
+	wantQ, _, err := CreateQuoteMessage(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotQ, err := QuoteHash(q); err != nil || !bytes.Equal(gotQ.Bytes(), wantQ) {
+		t.Errorf("QuoteHash = %x, %v, want %x", gotQ, err, wantQ)
+	}
+
+	tr := testTransfer
+	tr.ChainID = chainID
+	wantT, _, err := CreateTransferMessage(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotT, err := TransferHash(tr); err != nil || !bytes.Equal(gotT.Bytes(), wantT) {
+		t.Errorf("TransferHash = %x, %v, want %x", gotT, err, wantT)
+	}
+}
+
+func TestHashRejectsWhatTypedDataRejects(t *testing.T) {
+	badQuotes := map[string]func(*Quote){
+		"maker":  func(q *Quote) { q.Maker = "not-an-address" },
+		"expiry": func(q *Quote) { q.Expiry = -1 },
+	}
+	for name, mutate := range badQuotes {
+		q := testQuote
+		mutate(&q)
+		if _, _, err := CreateQuoteMessage(q); err == nil {
+			t.Errorf("%s: CreateQuoteMessage accepted invalid quote", name)
+		}
+		if _, err := QuoteHash(q); err == nil {
+			t.Errorf("%s: QuoteHash accepted invalid quote", name)
+		}
+	}
+
+	tr := testTransfer
+	tr.Asset = "0x1234"
+	if _, _, err := CreateTransferMessage(tr); err == nil {
+		t.Error("CreateTransferMessage accepted invalid asset")
+	}
+	if _, err := TransferHash(tr); err == nil {
+		t.Error("TransferHash accepted invalid asset")
+	}
+}
+
+func TestAccountSignRecovers(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := Account{Public: crypto.PubkeyToAddress(key.PublicKey), Private: key}
+	hash, err := QuoteHash(testQuote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := account.Sign(hash.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := RecoverSigner(hash.Bytes(), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer != account.Public {
+		t.Errorf("RecoverSigner = %s, want %s", signer, account.Public)
+	}
+}
+
+func TestNormalizeSignature(t *testing.T) {
+	sig := signQuote(t, newTestKey(t), testQuote)
+	raw := common.FromHex(sig)
+	raw[64] -= 27
+	walletSig := strings.ToUpper(hexutil.Encode(raw)[2:])
+
+	for name, in := range map[string]string{"27/28": sig, "0/1 upper case": "0x" + walletSig} {
+		got, err := NormalizeSignature(in)
+		if err != nil || got != sig {
+			t.Errorf("%s: NormalizeSignature = %s, %v, want %s", name, got, err, sig)
+		}
+	}
+
+	raw[64] = 29
+	for name, in := range map[string]string{
+		"bad recovery id": hexutil.Encode(raw),
+		"short":           sig[:len(sig)-2],
+		"not hex":         "0xzz",
+	} {
+		if got, err := NormalizeSignature(in); err == nil {
+			t.Errorf("%s: NormalizeSignature = %s, want an error", name, got)
+		}
+	}
+}
+
+func BenchmarkCreateQuoteMessage(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, _, err := CreateQuoteMessage(testQuote); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkQuoteHash(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := QuoteHash(testQuote); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCreateTransferMessage(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, _, err := CreateTransferMessage(testTransfer); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTransferHash(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := TransferHash(testTransfer); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+package ryskcore
+
+//go:generate go run github.com/ethereum/go-ethereum/cmd/abigen --abi abi/IERC20.json --pkg ryskcore --type IERC20 --out erc20.go
@@ -0,0 +1,153 @@
+package ryskcore
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	crypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// KeySource returns a hex-encoded private key. Surrounding whitespace and a 0x prefix
+// are accepted. The returned buffer is zeroed once the key has been parsed.
+type KeySource func() ([]byte, error)
+
+// KeyFromString returns a KeySource for a key given inline.
+func KeyFromString(key string) KeySource {
+	return func() ([]byte, error) {
+		return []byte(key), nil
+	}
+}
+
+// KeyFromFile returns a KeySource reading the key from a file, e.g. a mounted secret.
+func KeyFromFile(path string) KeySource {
+	return func() ([]byte, error) {
+		return os.ReadFile(path)
+	}
+}
+
+// KeyFromEnv returns a KeySource reading the key from an environment variable.
+func KeyFromEnv(name string) KeySource {
+	return func() ([]byte, error) {
+		key, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", name)
+		}
+		return []byte(key), nil
+	}
+}
+
+// KeyFromReader returns a KeySource reading the first line of r, e.g. stdin. It does
+// not wait for r to be closed, so the key can be piped in by a process that keeps
+// running, and nothing past the first line is consumed.
+func KeyFromReader(r io.Reader) KeySource {
+	return func() ([]byte, error) {
+		return readKey(r, true)
+	}
+}
+
+// KeyFromCommand returns a KeySource running command through sh and reading its
+// stdout, e.g. `pass show rysk/maker`. The command's stderr is passed through so that
+// tools like gpg can prompt for a passphrase.
+func KeyFromCommand(ctx context.Context, command string) KeySource {
+	return func() ([]byte, error) {
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Stdin = os.Stdin
+		cmd.Stderr = os.Stderr
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("private key command failed: %w", err)
+		}
+		key, err := readKey(stdout, false)
+		if err != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+			return nil, fmt.Errorf("private key command: %w", err)
+		}
+		if err := cmd.Wait(); err != nil {
+			clear(key)
+			return nil, fmt.Errorf("private key command failed: %w", err)
+		}
+		return key, nil
+	}
+}
+
+// maxKeyInput bounds what readKey reads. A hex key with a 0x prefix is 66 bytes, the
+// rest leaves room for whitespace.
+const maxKeyInput = 1024
+
+// readKey reads r up to the end of the first line if line is set, or else to the end
+// of input. It reads a byte at a time into the returned buffer, so that it never reads
+// past the line and the buffer, which the caller zeroes, is the only copy of the key.
+func readKey(r io.Reader, line bool) ([]byte, error) {
+	buf := make([]byte, maxKeyInput)
+	n := 0
+	for {
+		if n == len(buf) {
+			clear(buf)
+			return nil, fmt.Errorf("private key input is longer than %d bytes", maxKeyInput)
+		}
+		m, err := r.Read(buf[n : n+1])
+		n += m
+		if line && m > 0 && buf[n-1] == '\n' {
+			return buf[:n], nil
+		}
+		if err == io.EOF {
+			if n == 0 {
+				return nil, errors.New("no private key on input")
+			}
+			return buf[:n], nil
+		}
+		if err != nil {
+			clear(buf)
+			return nil, err
+		}
+	}
+}
+
+// NewAccountFromKeySource creates an Account from the key returned by src.
+func NewAccountFromKeySource(src KeySource) (account Account, err error) {
+	raw, err := src()
+	if err != nil {
+		return account, err
+	}
+	defer clear(raw)
+
+	account.Private, err = parsePrivateKey(raw)
+	if err != nil {
+		return account, err
+	}
+	account.Public = crypto.PubkeyToAddress(account.Private.PublicKey)
+	return account, nil
+}
+
+// Clear zeroes the account's private key. The account cannot sign afterwards.
+func (a *Account) Clear() {
+	if a.Private != nil && a.Private.D != nil {
+		clear(a.Private.D.Bits())
+		a.Private.D.SetInt64(0)
+	}
+}
+
+// parsePrivateKey decodes a hex key, ignoring surrounding whitespace and a 0x prefix.
+func parsePrivateKey(raw []byte) (*ecdsa.PrivateKey, error) {
+	key := bytes.TrimSpace(raw)
+	if len(key) >= 2 && key[0] == '0' && (key[1] == 'x' || key[1] == 'X') {
+		key = key[2:]
+	}
+	buf := make([]byte, hex.DecodedLen(len(key)))
+	defer clear(buf)
+	if _, err := hex.Decode(buf, key); err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return crypto.ToECDSA(buf)
+}
@@ -0,0 +1,117 @@
+package ryskcore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testKey     = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testKeyAddr = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+)
+
+func TestKeyFromReader(t *testing.T) {
+	t.Parallel()
+	for _, tt := range []struct {
+		name, input string
+	}{
+		{"line", testKey + "\n"},
+		{"crlf", testKey + "\r\n"},
+		{"prefix and padding", "  0x" + testKey + " \n"},
+		{"no trailing newline", testKey},
+		{"first line only", testKey + "\nsomething else\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			account, err := NewAccountFromKeySource(KeyFromReader(strings.NewReader(tt.input)))
+			if err != nil {
+				t.Fatalf("NewAccountFromKeySource: %v", err)
+			}
+			if account.Public.Hex() != testKeyAddr {
+				t.Errorf("address = %s, want %s", account.Public.Hex(), testKeyAddr)
+			}
+		})
+	}
+}
+
+func TestKeyFromReaderErrors(t *testing.T) {
+	t.Parallel()
+	for _, tt := range []struct {
+		name  string
+		input io.Reader
+	}{
+		{"empty", strings.NewReader("")},
+		{"empty line", strings.NewReader("\n" + testKey + "\n")},
+		{"not hex", strings.NewReader("not a key\n")},
+		{"read error", io.MultiReader(strings.NewReader(testKey[:10]), &errReader{errors.New("boom")})},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAccountFromKeySource(KeyFromReader(tt.input)); err == nil {
+				t.Error("NewAccountFromKeySource succeeded")
+			}
+		})
+	}
+}
+
+// TestKeyFromReaderDoesNotWaitForEOF reads a key from a pipe whose writer stays
+// open, like stdin of a process fed by a long-running secret helper.
+func TestKeyFromReaderDoesNotWaitForEOF(t *testing.T) {
+	t.Parallel()
+	r, w := io.Pipe()
+	defer w.Close()
+	go w.Write([]byte(testKey + "\n"))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := NewAccountFromKeySource(KeyFromReader(r))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("NewAccountFromKeySource: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("KeyFromReader waited for the end of input")
+	}
+}
+
+func TestKeyFromReaderLeavesRestOfInput(t *testing.T) {
+	t.Parallel()
+	r := strings.NewReader(testKey + "\nrest\n")
+	if _, err := NewAccountFromKeySource(KeyFromReader(r)); err != nil {
+		t.Fatalf("NewAccountFromKeySource: %v", err)
+	}
+	if rest, _ := io.ReadAll(r); string(rest) != "rest\n" {
+		t.Errorf("input left after the key = %q, want %q", rest, "rest\n")
+	}
+}
+
+func TestKeyFromCommand(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	account, err := NewAccountFromKeySource(KeyFromCommand(ctx, "printf '0x%s\\n' "+testKey))
+	if err != nil {
+		t.Fatalf("NewAccountFromKeySource: %v", err)
+	}
+	if account.Public.Hex() != testKeyAddr {
+		t.Errorf("address = %s, want %s", account.Public.Hex(), testKeyAddr)
+	}
+
+	for name, command := range map[string]string{
+		"no output": "true",
+		"failure":   "echo " + testKey + "; exit 1",
+		"too long":  "yes " + testKey,
+	} {
+		if _, err := NewAccountFromKeySource(KeyFromCommand(ctx, command)); err == nil {
+			t.Errorf("NewAccountFromKeySource(%s) succeeded", name)
+		}
+	}
+}
+
+type errReader struct{ err error }
+
+func (r *errReader) Read([]byte) (int, error) { return 0, r.err }
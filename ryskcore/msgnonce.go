@@ -0,0 +1,310 @@
+package ryskcore
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+// A NonceStrategy generates the nonces of signed messages, such as quotes and
+// transfers. Their EIP-712 type is string, so any unique string will do.
+type NonceStrategy interface {
+	// Next returns a new nonce, given the last one it returned for the account, or
+	// "" if there is none.
+	Next(last string) (string, error)
+}
+
+// CounterNonces counts up from 1 per account.
+type CounterNonces struct{}
+
+func (CounterNonces) Next(last string) (string, error) {
+	if last == "" {
+		return "1", nil
+	}
+	n, err := strconv.ParseUint(last, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("last nonce %q is not a counter", last)
+	}
+	return strconv.FormatUint(n+1, 10), nil
+}
+
+// TimestampNonces are the current Unix time in milliseconds followed by six random
+// digits, so that nonces from several processes or machines do not collide. They
+// are decimal and grow over time.
+type TimestampNonces struct{}
+
+func (TimestampNonces) Next(string) (string, error) {
+	r, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d%06d", time.Now().UnixMilli(), r), nil
+}
+
+// UUIDNonces are random version 4 UUIDs.
+type UUIDNonces struct{}
+
+func (UUIDNonces) Next(string) (string, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// NonceStrategies are the NonceStrategy values by name. Add to it to plug in
+// another strategy.
+var NonceStrategies = map[string]NonceStrategy{
+	"counter":   CounterNonces{},
+	"timestamp": TimestampNonces{},
+	"uuid":      UUIDNonces{},
+}
+
+// GetNonceStrategy returns the NonceStrategy called name.
+func GetNonceStrategy(name string) (NonceStrategy, error) {
+	s, ok := NonceStrategies[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(NonceStrategies))
+		for n := range NonceStrategies {
+			names = append(names, n)
+		}
+		slices.Sort(names)
+		return nil, fmt.Errorf("unknown nonce strategy %q, expected one of %v", name, names)
+	}
+	return s, nil
+}
+
+// ErrNonceUsed is returned by MessageNonces.Use for a nonce the account already used.
+var ErrNonceUsed = errors.New("nonce already used")
+
+// UsedNonceRetention is how long MessageNonces remembers a nonce given to Use or
+// generated by a strategy whose nonces are not decimal.
+const UsedNonceRetention = 30 * 24 * time.Hour
+
+// MessageNonces hands out the nonces of signed messages per account and refuses
+// nonces the account already used, so that a message is never signed twice with
+// the same nonce.
+//
+// Decimal nonces, such as those of CounterNonces and TimestampNonces, are generated
+// in increasing order, so only the range each strategy generated is kept, in a JSON
+// file. Any other nonce is recorded as a file named after its hash, in a directory
+// per account and day next to it. Checking a nonce looks up one file per day of
+// UsedNonceRetention, and days older than that are removed. Both are guarded by a
+// file lock, shared by every process using the same path.
+type MessageNonces struct {
+	mu   sync.Mutex
+	path string
+	used string
+}
+
+type messageNonceState struct {
+	// Generated is the range of decimal nonces generated for the account by each
+	// strategy.
+	Generated map[string]*nonceRange `json:"generated,omitempty"`
+}
+
+// nonceRange is an inclusive range of decimal nonces.
+type nonceRange struct {
+	First string `json:"first"`
+	Last  string `json:"last"`
+}
+
+func (r *nonceRange) contains(n *big.Int) bool {
+	first, _ := decimalNonce(r.First)
+	last, _ := decimalNonce(r.Last)
+	return first != nil && last != nil && first.Cmp(n) <= 0 && n.Cmp(last) <= 0
+}
+
+// NewMessageNonces returns a MessageNonces keeping its state at path, and the nonces
+// it records in a directory named after path.
+func NewMessageNonces(path string) (*MessageNonces, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	used := strings.TrimSuffix(path, filepath.Ext(path)) + "_used"
+	return &MessageNonces{path: path, used: used}, nil
+}
+
+// Next generates a nonce for account with the strategy called strategy. A decimal
+// nonce is raised above the last one the strategy generated, if needed, and extends
+// its range; any other nonce is recorded as used. Nonces given to Use are skipped.
+func (m *MessageNonces) Next(account common.Address, strategy string) (string, error) {
+	strategy = strings.ToLower(strategy)
+	gen, err := GetNonceStrategy(strategy)
+	if err != nil {
+		return "", err
+	}
+	var nonce string
+	err = m.update(account, func(s *messageNonceState, used *usedNonces) error {
+		generated := s.Generated[strategy]
+		for {
+			var last string
+			if generated != nil {
+				last = generated.Last
+			}
+			var err error
+			if nonce, err = gen.Next(last); err != nil {
+				return err
+			}
+			n, decimal := decimalNonce(nonce)
+			if decimal && generated != nil {
+				if l, _ := decimalNonce(generated.Last); l != nil && n.Cmp(l) <= 0 {
+					nonce = n.Add(l, big.NewInt(1)).String()
+				}
+			}
+			if decimal {
+				if generated == nil {
+					generated = &nonceRange{First: nonce}
+					s.Generated[strategy] = generated
+				}
+				generated.Last = nonce
+			}
+			if _, ok, err := used.lookup(nonce); err != nil {
+				return err
+			} else if ok {
+				continue
+			}
+			if decimal {
+				return nil
+			}
+			return used.record(nonce)
+		}
+	})
+	return nonce, err
+}
+
+// Use records nonce as used by account, or returns ErrNonceUsed if it already was,
+// or is a decimal nonce within the range a strategy generated for account.
+func (m *MessageNonces) Use(account common.Address, nonce string) error {
+	return m.update(account, func(s *messageNonceState, used *usedNonces) error {
+		if n, ok := decimalNonce(nonce); ok {
+			for strategy, generated := range s.Generated {
+				if generated.contains(n) {
+					return fmt.Errorf("%w by %s: %s is within the nonces generated with %s, %s to %s", ErrNonceUsed, account.Hex(), nonce, strategy, generated.First, generated.Last)
+				}
+			}
+		}
+		at, ok, err := used.lookup(nonce)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return fmt.Errorf("%w by %s on %s: %s", ErrNonceUsed, account.Hex(), at.Format(time.DateOnly), nonce)
+		}
+		return used.record(nonce)
+	})
+}
+
+// update applies fn to the state of account and the nonces it used, after
+// forgetting nonces used more than UsedNonceRetention ago.
+func (m *MessageNonces) update(account common.Address, fn func(s *messageNonceState, used *usedNonces) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state := map[string]*messageNonceState{}
+	return updateStateFile(m.path, &state, func() error {
+		key := strings.ToLower(account.Hex())
+		s := state[key]
+		if s == nil {
+			s = &messageNonceState{}
+			state[key] = s
+		}
+		if s.Generated == nil {
+			s.Generated = make(map[string]*nonceRange)
+		}
+		used := &usedNonces{dir: filepath.Join(m.used, key), now: time.Now().UTC()}
+		if err := used.prune(); err != nil {
+			return err
+		}
+		return fn(s, used)
+	})
+}
+
+// usedNonces are the nonces an account used, as empty files named after their
+// hash in a directory per UTC day.
+type usedNonces struct {
+	dir string
+	now time.Time
+}
+
+// days returns the directories of the days within UsedNonceRetention, newest first.
+func (u *usedNonces) days() []string {
+	n := int(UsedNonceRetention / (24 * time.Hour))
+	days := make([]string, 0, n+1)
+	for i := 0; i <= n; i++ {
+		days = append(days, filepath.Join(u.dir, u.now.AddDate(0, 0, -i).Format(time.DateOnly)))
+	}
+	return days
+}
+
+// lookup returns the day nonce was used on, if it was.
+func (u *usedNonces) lookup(nonce string) (time.Time, bool, error) {
+	name := usedNonceFile(nonce)
+	for _, day := range u.days() {
+		if _, err := os.Stat(filepath.Join(day, name)); err == nil {
+			at, err := time.Parse(time.DateOnly, filepath.Base(day))
+			return at, true, err
+		} else if !os.IsNotExist(err) {
+			return time.Time{}, false, err
+		}
+	}
+	return time.Time{}, false, nil
+}
+
+// record records nonce as used today.
+func (u *usedNonces) record(nonce string) error {
+	day := u.days()[0]
+	if err := os.MkdirAll(day, 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(day, usedNonceFile(nonce)), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// prune removes the days older than UsedNonceRetention.
+func (u *usedNonces) prune() error {
+	entries, err := os.ReadDir(u.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	days := u.days()
+	oldest := filepath.Base(days[len(days)-1])
+	for _, e := range entries {
+		if e.Name() < oldest {
+			if err := os.RemoveAll(filepath.Join(u.dir, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func usedNonceFile(nonce string) string {
+	return hex.EncodeToString(crypto.Keccak256([]byte(nonce)))
+}
+
+// decimalNonce parses nonce if it is a decimal number without sign.
+func decimalNonce(nonce string) (*big.Int, bool) {
+	if nonce == "" || strings.TrimLeft(nonce, "0123456789") != "" {
+		return nil, false
+	}
+	return new(big.Int).SetString(nonce, 10)
+}
@@ -0,0 +1,197 @@
+package ryskcore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	testMaker = common.HexToAddress(testQuote.Maker)
+	testOther = common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913")
+)
+
+func newTestMessageNonces(t *testing.T) *MessageNonces {
+	t.Helper()
+	m, err := NewMessageNonces(filepath.Join(t.TempDir(), "state", "message_nonces.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestMessageNoncesCounter(t *testing.T) {
+	t.Parallel()
+	m := newTestMessageNonces(t)
+	next := func(account common.Address) string {
+		t.Helper()
+		nonce, err := m.Next(account, "counter")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return nonce
+	}
+
+	if got := next(testMaker); got != "1" {
+		t.Errorf("first nonce = %s, want 1", got)
+	}
+	if err := m.Use(testMaker, "3"); err != nil {
+		t.Fatal(err)
+	}
+	if got := next(testMaker); got != "2" {
+		t.Errorf("second nonce = %s, want 2", got)
+	}
+	if got := next(testMaker); got != "4" {
+		t.Errorf("third nonce = %s, want 4, skipping the used 3", got)
+	}
+	if _, err := m.Next(testMaker, "uuid"); err != nil {
+		t.Fatal(err)
+	}
+	if got := next(testMaker); got != "5" {
+		t.Errorf("nonce after a uuid = %s, want 5", got)
+	}
+	if got := next(testOther); got != "1" {
+		t.Errorf("first nonce of another account = %s, want 1", got)
+	}
+
+	// The state is shared through the file, not kept in memory.
+	reopened, err := NewMessageNonces(m.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := reopened.Next(testMaker, "counter"); err != nil || got != "6" {
+		t.Errorf("nonce after reopening = %s, %v, want 6", got, err)
+	}
+}
+
+func TestMessageNoncesRefuseReuse(t *testing.T) {
+	t.Parallel()
+	m := newTestMessageNonces(t)
+	if err := m.Use(testMaker, "42"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Use(testMaker, "42"); !errors.Is(err, ErrNonceUsed) {
+		t.Errorf("reusing a nonce: %v, want ErrNonceUsed", err)
+	}
+	if err := m.Use(testOther, "42"); err != nil {
+		t.Errorf("using the nonce of another account: %v", err)
+	}
+	nonce, err := m.Next(testMaker, "uuid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Use(testMaker, nonce); !errors.Is(err, ErrNonceUsed) {
+		t.Errorf("reusing a generated nonce: %v, want ErrNonceUsed", err)
+	}
+}
+
+func TestMessageNoncesKeepGeneratedRanges(t *testing.T) {
+	t.Parallel()
+	m := newTestMessageNonces(t)
+	for range 3 {
+		if _, err := m.Next(testMaker, "counter"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first, err := m.Next(testMaker, "timestamp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := m.Next(testMaker, "timestamp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := decimalNonce(first)
+	if b, _ := decimalNonce(second); a == nil || b == nil || b.Cmp(a) <= 0 {
+		t.Errorf("timestamp nonces %s then %s, want increasing decimals", first, second)
+	}
+
+	// Decimal nonces are not recorded one by one.
+	if _, err := os.Stat(m.used); !os.IsNotExist(err) {
+		t.Errorf("used nonces directory after decimal nonces: %v, want none", err)
+	}
+	for _, nonce := range []string{"2", "3", first, second} {
+		if err := m.Use(testMaker, nonce); !errors.Is(err, ErrNonceUsed) {
+			t.Errorf("Use(%s) within a generated range: %v, want ErrNonceUsed", nonce, err)
+		}
+	}
+	for _, nonce := range []string{"0", "5"} {
+		if err := m.Use(testMaker, nonce); err != nil {
+			t.Errorf("Use(%s) outside the generated ranges: %v", nonce, err)
+		}
+	}
+	if err := m.Use(testOther, "2"); err != nil {
+		t.Errorf("Use(2) by another account: %v", err)
+	}
+	if got, err := m.Next(testMaker, "counter"); err != nil || got != "4" {
+		t.Errorf("counter after Use(5) = %s, %v, want 4", got, err)
+	}
+	if got, err := m.Next(testMaker, "counter"); err != nil || got != "6" {
+		t.Errorf("counter after 4 = %s, %v, want 6, skipping the used 5", got, err)
+	}
+}
+
+func TestMessageNoncesForgetOldNonces(t *testing.T) {
+	t.Parallel()
+	m := newTestMessageNonces(t)
+	nonce, err := m.Next(testMaker, "uuid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(m.used, strings.ToLower(testMaker.Hex()))
+	today := filepath.Join(dir, time.Now().UTC().Format(time.DateOnly))
+	if _, err := os.Stat(filepath.Join(today, usedNonceFile(nonce))); err != nil {
+		t.Fatalf("uuid nonce not recorded: %v", err)
+	}
+
+	old := filepath.Join(dir, time.Now().UTC().Add(-UsedNonceRetention-48*time.Hour).Format(time.DateOnly))
+	if err := os.Rename(today, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Use(testMaker, nonce); err != nil {
+		t.Errorf("Use of a nonce older than UsedNonceRetention: %v", err)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("day older than UsedNonceRetention: %v, want it removed", err)
+	}
+}
+
+func TestNonceStrategies(t *testing.T) {
+	for name, pattern := range map[string]string{
+		"counter":   `^1$`,
+		"timestamp": `^\d{19}$`,
+		"UUID":      `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+	} {
+		s, err := GetNonceStrategy(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, err := s.Next("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !regexp.MustCompile(pattern).MatchString(a) {
+			t.Errorf("%s nonce %q does not match %s", name, a, pattern)
+		}
+		if name != "counter" {
+			if b, _ := s.Next(a); a == b {
+				t.Errorf("%s returned %s twice", name, a)
+			}
+		}
+	}
+	if _, err := GetNonceStrategy("random"); err == nil {
+		t.Error("GetNonceStrategy(random) succeeded")
+	}
+	if _, err := newTestMessageNonces(t).Next(testMaker, "random"); err == nil {
+		t.Error("Next with an unknown strategy succeeded")
+	}
+	if _, err := (CounterNonces{}).Next("abc"); err == nil {
+		t.Error("counting up from a non counter nonce succeeded")
+	}
+}
@@ -0,0 +1,158 @@
+package ryskcore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/goccy/go-json"
+	"github.com/gofrs/flock"
+)
+
+// PendingNonceReader is what NonceManager needs from a node.
+type PendingNonceReader interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// NonceResyncAfter is how long NonceManager keeps handing out nonces above the
+// node's pending nonce after the last one. Past it, the gap is assumed to come from
+// nonces that were reserved but never sent, and the next nonce is the pending one.
+const NonceResyncAfter = 2 * time.Minute
+
+// NonceManager hands out transaction nonces per account and chain, so that
+// transactions sent close together, from one process or several, do not collide.
+// Nonces are persisted in a JSON state file guarded by a file lock.
+type NonceManager struct {
+	mu   sync.Mutex
+	path string
+}
+
+type nonceState struct {
+	// Next is the nonce after the last one handed out.
+	Next uint64 `json:"next"`
+	// At is when the last nonce was handed out, in Unix seconds.
+	At int64 `json:"at"`
+}
+
+// NewNonceManager returns a NonceManager persisting its state at path.
+func NewNonceManager(path string) (*NonceManager, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	return &NonceManager{path: path}, nil
+}
+
+// Next reserves the next nonce of account on chainID: the one after the last nonce
+// handed out, so that transactions the node has not seen yet are not replaced, or the
+// node's pending nonce if that is higher or the last nonce was handed out more than
+// NonceResyncAfter ago.
+func (m *NonceManager) Next(ctx context.Context, chainID int, account common.Address, node PendingNonceReader) (uint64, error) {
+	pending, err := node.PendingNonceAt(ctx, account)
+	if err != nil {
+		return 0, err
+	}
+	var nonce uint64
+	err = m.update(func(state map[string]nonceState) {
+		key := nonceKey(chainID, account)
+		now := time.Now()
+		nonce = pending
+		if s := state[key]; s.Next > pending && now.Sub(time.Unix(s.At, 0)) < NonceResyncAfter {
+			nonce = s.Next
+		}
+		state[key] = nonceState{Next: nonce + 1, At: now.Unix()}
+	})
+	return nonce, err
+}
+
+// Release returns a nonce that was reserved but never used by a sent transaction,
+// provided no later nonce has been handed out since.
+func (m *NonceManager) Release(chainID int, account common.Address, nonce uint64) error {
+	return m.update(func(state map[string]nonceState) {
+		key := nonceKey(chainID, account)
+		if s := state[key]; s.Next == nonce+1 {
+			s.Next = nonce
+			state[key] = s
+		}
+	})
+}
+
+// Reset forgets the nonces handed out for account on chainID, so that the next one
+// is taken from the node again.
+func (m *NonceManager) Reset(chainID int, account common.Address) error {
+	return m.update(func(state map[string]nonceState) {
+		delete(state, nonceKey(chainID, account))
+	})
+}
+
+// update applies fn to the state file under an exclusive lock.
+func (m *NonceManager) update(fn func(state map[string]nonceState)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state := map[string]nonceState{}
+	return updateStateFile(m.path, &state, func() error {
+		fn(state)
+		return nil
+	})
+}
+
+// updateStateFile loads the JSON file at path into state, calls fn and saves state
+// back, holding an exclusive lock on path.lock throughout so that processes sharing
+// the file do not race. A missing file leaves state as is. If fn fails, the file is
+// not written.
+func updateStateFile(path string, state any, fn func() error) error {
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	defer lock.Unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, state); err != nil {
+			return fmt.Errorf("invalid state file %s: %w", path, err)
+		}
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	if data, err = json.Marshal(state); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+func nonceKey(chainID int, account common.Address) string {
+	return fmt.Sprintf("%d:%s", chainID, strings.ToLower(account.Hex()))
+}
+
+// isNonceTooLow reports whether err is a node rejecting a nonce that is already used.
+func isNonceTooLow(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
+
+// writeFileAtomic replaces path with data, so readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
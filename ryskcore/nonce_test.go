@@ -0,0 +1,151 @@
+package ryskcore
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// pendingNonces is a PendingNonceReader returning a fixed pending nonce.
+type pendingNonces uint64
+
+func (p pendingNonces) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	return uint64(p), nil
+}
+
+var testAccount = common.HexToAddress("0x2c7536E3605D9C16a7a3D7b1898e529396a65c23")
+
+func newTestNonceManager(t *testing.T) *NonceManager {
+	t.Helper()
+	m, err := NewNonceManager(filepath.Join(t.TempDir(), "state", "nonces.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func nextNonce(t *testing.T, m *NonceManager, chainID int, pending uint64) uint64 {
+	t.Helper()
+	nonce, err := m.Next(context.Background(), chainID, testAccount, pendingNonces(pending))
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	return nonce
+}
+
+func TestNonceManagerNext(t *testing.T) {
+	t.Parallel()
+	m := newTestNonceManager(t)
+
+	// Nonces not seen by the node yet are not handed out again.
+	for want := uint64(5); want < 8; want++ {
+		if got := nextNonce(t, m, 1, 5); got != want {
+			t.Errorf("Next = %d, want %d", got, want)
+		}
+	}
+	// The node's pending nonce wins once it is ahead.
+	if got := nextNonce(t, m, 1, 20); got != 20 {
+		t.Errorf("Next with pending 20 = %d, want 20", got)
+	}
+	// Chains are independent.
+	if got := nextNonce(t, m, 2, 3); got != 3 {
+		t.Errorf("Next on another chain = %d, want 3", got)
+	}
+}
+
+func TestNonceManagerResyncsStaleGap(t *testing.T) {
+	t.Parallel()
+	m := newTestNonceManager(t)
+	nextNonce(t, m, 1, 5)
+	nextNonce(t, m, 1, 5)
+
+	// Nonce 6 was never sent. Once the last nonce is older than NonceResyncAfter,
+	// the node's pending nonce is used again instead of leaving a gap at 6.
+	err := m.update(func(state map[string]nonceState) {
+		s := state[nonceKey(1, testAccount)]
+		s.At = time.Now().Add(-NonceResyncAfter - time.Second).Unix()
+		state[nonceKey(1, testAccount)] = s
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nextNonce(t, m, 1, 6); got != 6 {
+		t.Errorf("Next after a stale gap = %d, want 6", got)
+	}
+	if got := nextNonce(t, m, 1, 6); got != 7 {
+		t.Errorf("Next after the resync = %d, want 7", got)
+	}
+}
+
+func TestNonceManagerReleaseAndReset(t *testing.T) {
+	t.Parallel()
+	m := newTestNonceManager(t)
+	first := nextNonce(t, m, 1, 0)
+	second := nextNonce(t, m, 1, 0)
+
+	// Only the last nonce handed out can be released.
+	if err := m.Release(1, testAccount, first); err != nil {
+		t.Fatal(err)
+	}
+	if got := nextNonce(t, m, 1, 0); got != second+1 {
+		t.Errorf("Next after releasing an earlier nonce = %d, want %d", got, second+1)
+	}
+	if err := m.Release(1, testAccount, second+1); err != nil {
+		t.Fatal(err)
+	}
+	if got := nextNonce(t, m, 1, 0); got != second+1 {
+		t.Errorf("Next after releasing the last nonce = %d, want %d", got, second+1)
+	}
+
+	if err := m.Reset(1, testAccount); err != nil {
+		t.Fatal(err)
+	}
+	if got := nextNonce(t, m, 1, 0); got != 0 {
+		t.Errorf("Next after Reset = %d, want the pending nonce 0", got)
+	}
+}
+
+// TestNonceManagerShared hands out nonces concurrently from managers sharing a state
+// file, as separate processes would.
+func TestNonceManagerShared(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "nonces.json")
+	const managers, perManager = 4, 25
+
+	var mu sync.Mutex
+	seen := make(map[uint64]bool)
+	var wg sync.WaitGroup
+	for range managers {
+		m, err := NewNonceManager(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range perManager {
+				nonce, err := m.Next(context.Background(), 1, testAccount, pendingNonces(0))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				if seen[nonce] {
+					t.Errorf("nonce %d handed out twice", nonce)
+				}
+				seen[nonce] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	for nonce := range uint64(managers * perManager) {
+		if !seen[nonce] {
+			t.Errorf("nonce %d was skipped", nonce)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package ryskcore
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// DefaultPermitVersion is used for tokens that do not expose version().
+const DefaultPermitVersion = "1"
+
+var PERMIT_TYPES = apitypes.Types{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	},
+	"Permit": {
+		{Name: "owner", Type: "address"},
+		{Name: "spender", Type: "address"},
+		{Name: "value", Type: "uint256"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "deadline", Type: "uint256"},
+	},
+}
+
+// Permit is a signed EIP-2612 permit, ready to be passed to the token's permit function.
+type Permit struct {
+	Token     common.Address `json:"token"`
+	Owner     common.Address `json:"owner"`
+	Spender   common.Address `json:"spender"`
+	Value     *big.Int       `json:"value"`
+	Nonce     *big.Int       `json:"nonce"`
+	Deadline  *big.Int       `json:"deadline"`
+	V         uint8          `json:"v"`
+	R         common.Hash    `json:"r"`
+	S         common.Hash    `json:"s"`
+	Signature string         `json:"signature"`
+}
+
+// SignPermit signs an EIP-2612 permit allowing spender to move value of token from the
+// account until deadline. The token name, version and the account's permit nonce are
+// read from the chain. An empty version is read from the token's version(), falling
+// back to DefaultPermitVersion. The computed domain is checked against the token's
+// DOMAIN_SEPARATOR so a wrong version is caught before the signature is used.
+func (a *Account) SignPermit(ctx context.Context, chainID int, caller bind.ContractCaller, token, spender common.Address, value, deadline *big.Int, version string) (*Permit, error) {
+	erc20, err := NewIERC20Caller(token, caller)
+	if err != nil {
+		return nil, err
+	}
+	opts := &bind.CallOpts{Context: ctx}
+
+	name, err := erc20.Name(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token name: %w", err)
+	}
+	if version == "" {
+		version, err = erc20.Version(opts)
+		if err != nil || version == "" {
+			version = DefaultPermitVersion
+		}
+	}
+	nonce, err := erc20.Nonces(opts, a.Public)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read permit nonce: %w", err)
+	}
+
+	typedData := &apitypes.TypedData{
+		Types:       PERMIT_TYPES,
+		PrimaryType: "Permit",
+		Domain: apitypes.TypedDataDomain{
+			Name:              name,
+			Version:           version,
+			ChainId:           math.NewHexOrDecimal256(int64(chainID)),
+			VerifyingContract: token.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"owner":    a.Public.Hex(),
+			"spender":  spender.Hex(),
+			"value":    (*math.HexOrDecimal256)(value),
+			"nonce":    (*math.HexOrDecimal256)(nonce),
+			"deadline": (*math.HexOrDecimal256)(deadline),
+		},
+	}
+
+	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		return nil, err
+	}
+	if onchain, err := erc20.DOMAINSEPARATOR(opts); err == nil && common.BytesToHash(domainSeparator) != common.Hash(onchain) {
+		return nil, fmt.Errorf("permit domain (name %q, version %q) does not match the token's DOMAIN_SEPARATOR", name, version)
+	}
+
+	hash, err := EncodeTypedData(typedData)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signTypedData(hash.Bytes(), a.Private)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Permit{
+		Token:     token,
+		Owner:     a.Public,
+		Spender:   spender,
+		Value:     value,
+		Nonce:     nonce,
+		Deadline:  deadline,
+		V:         sig[64],
+		R:         common.BytesToHash(sig[:32]),
+		S:         common.BytesToHash(sig[32:64]),
+		Signature: hexutil.Encode(sig),
+	}, nil
+}
+
+// SubmitPermit sends p to its token's permit function and waits for it to be mined.
+// Anyone can submit a permit; the account only pays for gas.
+func (a *Account) SubmitPermit(ctx context.Context, chainID int, client Backend, p *Permit, txOpts TxOptions) (*TxResult, error) {
+	erc20, err := NewIERC20(p.Token, client)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := a.transact(ctx, chainID, client, txOpts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return erc20.Permit(opts, p.Owner, p.Spender, p.Value, p.Deadline, p.V, p.R, p.S)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a.complete(ctx, client, tx, txOpts)
+}
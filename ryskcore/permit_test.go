@@ -0,0 +1,94 @@
+package ryskcore
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSignAndSubmitPermit(t *testing.T) {
+	t.Parallel()
+	chain := newTestChain(t)
+	ctx := context.Background()
+	spender := chain.mmarket
+	deadline := big.NewInt(time.Now().Add(time.Hour).Unix())
+
+	permit, err := chain.account.SignPermit(ctx, testChainID, chain.client, chain.token, spender, big.NewInt(1000), deadline, "")
+	if err != nil {
+		t.Fatalf("SignPermit: %v", err)
+	}
+	if permit.Owner != chain.account.Public || permit.Spender != spender || permit.Nonce.Sign() != 0 {
+		t.Errorf("SignPermit = %+v, want nonce 0 from the account to the spender", permit)
+	}
+	if permit.V != 27 && permit.V != 28 {
+		t.Errorf("SignPermit v = %d, want 27 or 28", permit.V)
+	}
+
+	forged := *permit
+	forged.Value = big.NewInt(2000)
+	if _, err := chain.account.SubmitPermit(ctx, testChainID, chain.client, &forged, TxOptions{}); err == nil {
+		t.Error("SubmitPermit accepted a permit whose value is not the signed one")
+	}
+
+	result, err := chain.account.SubmitPermit(ctx, testChainID, chain.client, permit, TxOptions{})
+	if err != nil {
+		t.Fatalf("SubmitPermit: %v", err)
+	}
+	if result.Status != TxStatusSuccess {
+		t.Errorf("SubmitPermit result = %+v, want a mined success", result)
+	}
+	if got := chain.allowance(t); got.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("allowance = %s, want 1000", got)
+	}
+	erc20, err := NewIERC20Caller(chain.token, chain.client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce, err := erc20.Nonces(&bind.CallOpts{}, chain.account.Public); err != nil || nonce.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("Nonces = %v, %v, want 1", nonce, err)
+	}
+
+	if _, err := chain.account.SubmitPermit(ctx, testChainID, chain.client, permit, TxOptions{}); err == nil {
+		t.Error("SubmitPermit replayed a used permit")
+	}
+}
+
+func TestSubmitPermitRejectsExpiredDeadline(t *testing.T) {
+	t.Parallel()
+	chain := newTestChain(t)
+	ctx := context.Background()
+
+	permit, err := chain.account.SignPermit(ctx, testChainID, chain.client, chain.token, chain.mmarket, big.NewInt(1000), big.NewInt(1), "")
+	if err != nil {
+		t.Fatalf("SignPermit: %v", err)
+	}
+	result, err := chain.account.SubmitPermit(ctx, testChainID, chain.client, permit, TxOptions{})
+	if result != nil {
+		t.Errorf("SubmitPermit with an expired deadline = %+v, want no result", result)
+	}
+	if reverted := checkRevert(t, err, "permit expired"); reverted.Hash != (common.Hash{}) {
+		t.Errorf("SubmitPermit sent %s, want the transaction not sent", reverted.Hash.Hex())
+	}
+
+	result, err = chain.account.SubmitPermit(ctx, testChainID, chain.client, permit, TxOptions{DryRun: true})
+	reverted := checkRevert(t, err, "permit expired")
+	if result != reverted || result.Simulation == nil || result.Simulation.Status != TxStatusReverted {
+		t.Errorf("SubmitPermit dry run = %+v, want the reverted simulation", result)
+	}
+}
+
+func TestSignPermitChecksDomain(t *testing.T) {
+	t.Parallel()
+	chain := newTestChain(t)
+	deadline := big.NewInt(time.Now().Add(time.Hour).Unix())
+
+	_, err := chain.account.SignPermit(context.Background(), testChainID, chain.client, chain.token, chain.mmarket, big.NewInt(1000), deadline, DefaultPermitVersion)
+	if err == nil || !strings.Contains(err.Error(), "DOMAIN_SEPARATOR") {
+		t.Errorf("SignPermit with version %q = %v, want a domain mismatch", DefaultPermitVersion, err)
+	}
+}
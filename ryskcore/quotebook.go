@@ -0,0 +1,131 @@
+package ryskcore
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// ParseQuoteNotification decodes a notification of the best quote on an RFQ, either
+// on its own or as the params or result of a JSON-RPC message. Messages without an
+// rfq id and a new best price are not quote notifications and return an error.
+func ParseQuoteNotification(data []byte) (QuoteNotification, error) {
+	var n QuoteNotification
+	if err := unmarshalPayload(data, &n); err != nil {
+		return QuoteNotification{}, fmt.Errorf("invalid quote notification: %w", err)
+	}
+	if n.RequestID == "" || n.NewBest == "" {
+		return QuoteNotification{}, errors.New("not a quote notification")
+	}
+	return n, nil
+}
+
+// QuoteStatus is where our quote on an RFQ stands against the best one.
+type QuoteStatus struct {
+	RFQID   string `json:"rfqId"`
+	Asset   string `json:"assetAddress"`
+	ChainID int    `json:"chainId"`
+	// Best is the best price on the RFQ and Yours our price, as last notified.
+	Best  string `json:"best"`
+	Yours string `json:"yours,omitempty"`
+	// Quote is the last quote we sent on the RFQ, if it went through the QuoteBook.
+	Quote *Quote `json:"quote,omitempty"`
+	// Outbid is set when another maker's price is the best.
+	Outbid bool `json:"outbid"`
+}
+
+// QuoteBook tracks the quotes we send and the notifications of the best quote per
+// RFQ. RFQs not heard of for MaxQuoteValidity are forgotten. It is safe for
+// concurrent use.
+type QuoteBook struct {
+	// OnOutbid, if set, is called when we are outbid on an RFQ, once per new best
+	// price. It is called synchronously, without holding the book's lock.
+	OnOutbid func(QuoteStatus)
+
+	mu   sync.Mutex
+	rfqs map[string]*quoteEntry
+}
+
+type quoteEntry struct {
+	status  QuoteStatus
+	updated time.Time
+}
+
+// NewQuoteBook returns an empty QuoteBook.
+func NewQuoteBook() *QuoteBook {
+	return &QuoteBook{rfqs: make(map[string]*quoteEntry)}
+}
+
+// Quoted records q as our quote on the RFQ rfqID.
+func (b *QuoteBook) Quoted(rfqID string, q Quote) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e := b.entry(rfqID, time.Now())
+	e.status.Asset, e.status.ChainID = q.AssetAddress, q.ChainID
+	e.status.Quote = &q
+}
+
+// Update records notification n and returns the status of its RFQ, calling OnOutbid
+// if another maker's price became the best.
+func (b *QuoteBook) Update(n QuoteNotification) QuoteStatus {
+	b.mu.Lock()
+	e := b.entry(n.RequestID, time.Now())
+	previousBest := e.status.Best
+	e.status.Asset, e.status.ChainID = n.Asset, n.ChainID
+	e.status.Best, e.status.Yours = n.NewBest, n.Yours
+
+	yours := n.Yours
+	if yours == "" && e.status.Quote != nil {
+		yours = e.status.Quote.Price.String()
+	}
+	e.status.Outbid = yours != "" && !samePrice(n.NewBest, yours)
+	status := e.status
+	b.mu.Unlock()
+
+	if status.Outbid && b.OnOutbid != nil && !samePrice(status.Best, previousBest) {
+		b.OnOutbid(status)
+	}
+	return status
+}
+
+// Status returns the status of the RFQ rfqID, if it is tracked.
+func (b *QuoteBook) Status(rfqID string) (QuoteStatus, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.rfqs[rfqID]
+	if !ok {
+		return QuoteStatus{}, false
+	}
+	return e.status, true
+}
+
+// entry returns the entry of rfqID, creating it if needed, and forgets stale ones.
+// b.mu must be held.
+func (b *QuoteBook) entry(rfqID string, now time.Time) *quoteEntry {
+	for id, e := range b.rfqs {
+		if now.Sub(e.updated) > MaxQuoteValidity {
+			delete(b.rfqs, id)
+		}
+	}
+	e, ok := b.rfqs[rfqID]
+	if !ok {
+		e = &quoteEntry{status: QuoteStatus{RFQID: rfqID}}
+		b.rfqs[rfqID] = e
+	}
+	e.updated = now
+	return e
+}
+
+// samePrice compares prices numerically when both are decimal or hex uint256
+// strings, and as strings otherwise.
+func samePrice(a, b string) bool {
+	var x, y math.HexOrDecimal256
+	if x.UnmarshalText([]byte(a)) != nil || y.UnmarshalText([]byte(b)) != nil {
+		return a == b
+	}
+	return (*big.Int)(&x).Cmp((*big.Int)(&y)) == 0
+}
@@ -0,0 +1,51 @@
+package ryskcore
+
+import "testing"
+
+func TestParseQuoteNotification(t *testing.T) {
+	n, err := ParseQuoteNotification([]byte(`{"jsonrpc":"2.0","method":"quoteNotification","params":{"rfqId":"rfq-1","assetAddress":"0xb67bfa7b488df4f2efa874f4e59242e9130ae61f","chainId":8453,"newBest":"1300","yours":"1250"}}`))
+	if err != nil {
+		t.Fatalf("ParseQuoteNotification: %v", err)
+	}
+	if n.RequestID != "rfq-1" || n.ChainID != CHAIN_ID_BASE || n.NewBest != "1300" || n.Yours != "1250" {
+		t.Errorf("ParseQuoteNotification = %+v", n)
+	}
+	for _, msg := range []string{testRFQJSON, `{"jsonrpc":"2.0","id":"1","result":"ok"}`, `not json`} {
+		if _, err := ParseQuoteNotification([]byte(msg)); err == nil {
+			t.Errorf("ParseQuoteNotification(%s) succeeded", msg)
+		}
+	}
+}
+
+func TestQuoteBookOutbid(t *testing.T) {
+	var outbids []QuoteStatus
+	book := NewQuoteBook()
+	book.OnOutbid = func(s QuoteStatus) { outbids = append(outbids, s) }
+
+	q := testQuote
+	q.Price = Uint256FromUint64(1250)
+	book.Quoted("rfq-1", q)
+
+	// Our price is the best, in another notation.
+	if s := book.Update(QuoteNotification{RequestID: "rfq-1", NewBest: "0x4e2", Yours: "1250"}); s.Outbid {
+		t.Errorf("Update with our price best = %+v, want not outbid", s)
+	}
+	s := book.Update(QuoteNotification{RequestID: "rfq-1", NewBest: "1300", Yours: "1250"})
+	if !s.Outbid || s.Quote == nil || s.Quote.Price.String() != "1250" {
+		t.Errorf("Update with a better price = %+v, want outbid with our quote", s)
+	}
+	// The same best again does not call OnOutbid again, a new best does.
+	book.Update(QuoteNotification{RequestID: "rfq-1", NewBest: "1300", Yours: "1250"})
+	book.Update(QuoteNotification{RequestID: "rfq-1", NewBest: "1350", Yours: "1250"})
+	if len(outbids) != 2 || outbids[0].Best != "1300" || outbids[1].Best != "1350" {
+		t.Errorf("OnOutbid calls = %+v, want the new bests 1300 and 1350", outbids)
+	}
+
+	// Without a price of ours, nobody outbid us.
+	if s := book.Update(QuoteNotification{RequestID: "rfq-2", NewBest: "1"}); s.Outbid {
+		t.Errorf("Update on an rfq we did not quote = %+v, want not outbid", s)
+	}
+	if _, ok := book.Status("rfq-2"); !ok {
+		t.Error("Status(rfq-2) not tracked")
+	}
+}
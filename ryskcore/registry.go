@@ -0,0 +1,147 @@
+package ryskcore
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/goccy/go-json"
+	"gopkg.in/yaml.v3"
+)
+
+// Chain describes a chain the protocol is deployed on.
+type Chain struct {
+	ChainID     int    `json:"chainId" yaml:"chainId"`
+	Name        string `json:"name" yaml:"name"`
+	Addresses   `yaml:",inline"`
+	Underlyings []common.Address `json:"underlyings,omitempty" yaml:"underlyings,omitempty"`
+	Assets      []AssetInfo      `json:"assets,omitempty" yaml:"assets,omitempty"`
+	RPCURLs     []string         `json:"rpcUrls,omitempty" yaml:"rpcUrls,omitempty"`
+	WSURL       string           `json:"wsUrl,omitempty" yaml:"wsUrl,omitempty"`
+}
+
+//go:embed chains.json
+var defaultChains []byte
+
+// CHAINS is the chain registry, keyed by chain id. It holds the embedded defaults
+// and anything merged in with LoadChains.
+var CHAINS = map[int]Chain{}
+
+func init() {
+	if err := mergeChains(defaultChains, ".json"); err != nil {
+		panic(fmt.Sprintf("invalid embedded chain registry: %v", err))
+	}
+}
+
+// LoadChains merges the chains in the JSON or YAML file at path into CHAINS.
+// The file holds a list of chains; a chain that is already registered is replaced.
+func LoadChains(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := mergeChains(data, filepath.Ext(path)); err != nil {
+		return fmt.Errorf("invalid chain registry %s: %w", path, err)
+	}
+	return nil
+}
+
+// GetChain returns the registered chain with chainID.
+func GetChain(chainID int) (Chain, error) {
+	chain, ok := CHAINS[chainID]
+	if !ok {
+		return Chain{}, fmt.Errorf("unknown chain %d, see `ryskV12 chains` for configured chains", chainID)
+	}
+	return chain, nil
+}
+
+// GetAddresses returns the protocol addresses on chainID.
+func GetAddresses(chainID int) (Addresses, error) {
+	chain, err := GetChain(chainID)
+	if err != nil {
+		return Addresses{}, err
+	}
+	return chain.Addresses, nil
+}
+
+// Asset returns the chain's strike asset for "strike", or the catalogued asset with
+// the given symbol. An address is returned as is, whether it is registered or not.
+func (c Chain) Asset(nameOrAddress string) (common.Address, error) {
+	if strings.EqualFold(nameOrAddress, "strike") {
+		if c.StrikeAsset == ZeroAddress {
+			return common.Address{}, fmt.Errorf("chain %d has no strike asset configured", c.ChainID)
+		}
+		return c.StrikeAsset, nil
+	}
+	if common.IsHexAddress(nameOrAddress) {
+		return common.HexToAddress(nameOrAddress), nil
+	}
+	info, err := c.LookupAsset(nameOrAddress)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return info.Address, nil
+}
+
+// Spender returns the protocol contract named "MMarket" or "MarginPool", matched
+// case-insensitively, or given by its address.
+func (c Chain) Spender(nameOrAddress string) (common.Address, error) {
+	var spender common.Address
+	switch strings.ToLower(strings.ReplaceAll(nameOrAddress, "_", "")) {
+	case "mmarket":
+		spender = c.MMarket
+	case "marginpool":
+		spender = c.MarginPool
+	default:
+		if common.IsHexAddress(nameOrAddress) {
+			if a := common.HexToAddress(nameOrAddress); a == c.MMarket || a == c.MarginPool {
+				spender = a
+			}
+		}
+	}
+	if spender != ZeroAddress {
+		return spender, nil
+	}
+	return common.Address{}, fmt.Errorf("%s is not a protocol contract on chain %d", nameOrAddress, c.ChainID)
+}
+
+// ChainIDs returns the ids of all registered chains in ascending order.
+func ChainIDs() []int {
+	ids := make([]int, 0, len(CHAINS))
+	for id := range CHAINS {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func mergeChains(data []byte, ext string) error {
+	var chains []Chain
+	var err error
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		// Addresses are decoded from the raw scalar, so they need no quotes even
+		// though YAML reads an unquoted 0x... as a number.
+		err = yaml.Unmarshal(data, &chains)
+	default:
+		err = json.Unmarshal(data, &chains)
+	}
+	if err != nil {
+		return err
+	}
+	for _, chain := range chains {
+		if chain.ChainID <= 0 {
+			return fmt.Errorf("chain %q has no chainId", chain.Name)
+		}
+		if err := checkAssets(chain.Assets); err != nil {
+			return fmt.Errorf("chain %d: %w", chain.ChainID, err)
+		}
+		CHAINS[chain.ChainID] = chain
+		ADDRESSES[chain.ChainID] = chain.Addresses
+	}
+	return nil
+}
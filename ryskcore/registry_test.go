@@ -0,0 +1,182 @@
+package ryskcore
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// restoreRegistry restores CHAINS and ADDRESSES when the test ends. Tests using it
+// modify the registry, so they must not run in parallel.
+func restoreRegistry(t *testing.T) {
+	t.Helper()
+	chains, addresses := maps.Clone(CHAINS), maps.Clone(ADDRESSES)
+	t.Cleanup(func() {
+		CHAINS, ADDRESSES = chains, addresses
+	})
+}
+
+func writeRegistry(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEmbeddedRegistry(t *testing.T) {
+	for _, id := range []int{CHAIN_ID_BASE, CHAIN_ID_BASE_SEPOLIA, CHAIN_ID_HYPE_TESTNET, CHAIN_ID_MONAD_TESTNET} {
+		chain, err := GetChain(id)
+		if err != nil {
+			t.Errorf("GetChain(%d): %v", id, err)
+			continue
+		}
+		if chain.MarginPool == ZeroAddress || chain.MMarket == ZeroAddress || chain.StrikeAsset == ZeroAddress || len(chain.RPCURLs) == 0 {
+			t.Errorf("chain %d = %+v, want protocol addresses and an RPC URL", id, chain)
+		}
+		if ADDRESSES[id] != chain.Addresses {
+			t.Errorf("ADDRESSES[%d] = %+v, want %+v", id, ADDRESSES[id], chain.Addresses)
+		}
+		for _, a := range chain.Assets {
+			if a.Role == RoleUnderlying && !slices.Contains(chain.Underlyings, a.Address) {
+				t.Errorf("chain %d catalogues underlying %s but does not list it in underlyings", id, a.Symbol)
+			}
+		}
+	}
+	if got := CHAINS[CHAIN_ID_BASE].StrikeAsset; got != common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913") {
+		t.Errorf("Base strike asset = %s, want USDC", got.Hex())
+	}
+	if _, err := GetChain(42); err == nil {
+		t.Error("GetChain(42) succeeded for an unregistered chain")
+	}
+}
+
+const testRegistryJSON = `[{
+	"chainId": 31337,
+	"name": "Local",
+	"marginPool": "0x00000000000000000000000000000000000000a1",
+	"mmarket": "0x00000000000000000000000000000000000000a2",
+	"strikeAsset": "0x00000000000000000000000000000000000000c1",
+	"underlyings": ["0x00000000000000000000000000000000000000e1"],
+	"assets": [{"symbol": "WETH", "address": "0x00000000000000000000000000000000000000e1", "decimals": 18, "role": "underlying"}],
+	"rpcUrls": ["http://localhost:8545"],
+	"wsUrl": "ws://localhost:8080/maker"
+}]`
+
+// testRegistryYAML is testRegistryJSON with unquoted addresses, which YAML would
+// read as integers.
+const testRegistryYAML = `
+- chainId: 31337
+  name: Local
+  marginPool: 0x00000000000000000000000000000000000000a1
+  mmarket: "0x00000000000000000000000000000000000000a2"
+  strikeAsset: 0x00000000000000000000000000000000000000c1
+  underlyings: [0x00000000000000000000000000000000000000e1]
+  assets:
+    - {symbol: WETH, address: 0x00000000000000000000000000000000000000e1, decimals: 18, role: underlying}
+  rpcUrls: ["http://localhost:8545"]
+  wsUrl: ws://localhost:8080/maker
+`
+
+func TestLoadChains(t *testing.T) {
+	want := Chain{
+		ChainID: 31337,
+		Name:    "Local",
+		Addresses: Addresses{
+			MarginPool:  common.HexToAddress("0xa1"),
+			MMarket:     common.HexToAddress("0xa2"),
+			StrikeAsset: common.HexToAddress("0xc1"),
+		},
+		Underlyings: []common.Address{common.HexToAddress("0xe1")},
+		Assets:      []AssetInfo{{Symbol: "WETH", Address: common.HexToAddress("0xe1"), Decimals: 18, Role: RoleUnderlying}},
+		RPCURLs:     []string{"http://localhost:8545"},
+		WSURL:       "ws://localhost:8080/maker",
+	}
+	for _, file := range []struct{ name, content string }{
+		{"chains.json", testRegistryJSON},
+		{"chains.yaml", testRegistryYAML},
+		{"chains.YML", testRegistryYAML},
+	} {
+		t.Run(file.name, func(t *testing.T) {
+			restoreRegistry(t)
+			if err := LoadChains(writeRegistry(t, file.name, file.content)); err != nil {
+				t.Fatalf("LoadChains: %v", err)
+			}
+			if got := CHAINS[31337]; !reflect.DeepEqual(got, want) {
+				t.Errorf("CHAINS[31337] = %+v, want %+v", got, want)
+			}
+			if got := ADDRESSES[31337]; got != want.Addresses {
+				t.Errorf("ADDRESSES[31337] = %+v, want %+v", got, want.Addresses)
+			}
+			if _, ok := CHAINS[CHAIN_ID_BASE]; !ok {
+				t.Error("LoadChains dropped the embedded chains")
+			}
+		})
+	}
+}
+
+func TestLoadChainsReplacesChain(t *testing.T) {
+	restoreRegistry(t)
+	path := writeRegistry(t, "chains.yaml", "- {chainId: 8453, name: Fork, mmarket: 0x00000000000000000000000000000000000000a2}\n")
+	if err := LoadChains(path); err != nil {
+		t.Fatalf("LoadChains: %v", err)
+	}
+	chain := CHAINS[CHAIN_ID_BASE]
+	if chain.Name != "Fork" || chain.MMarket != common.HexToAddress("0xa2") || chain.StrikeAsset != ZeroAddress {
+		t.Errorf("CHAINS[%d] = %+v, want the loaded chain only", CHAIN_ID_BASE, chain)
+	}
+	if ADDRESSES[CHAIN_ID_BASE] != chain.Addresses {
+		t.Errorf("ADDRESSES[%d] = %+v, want %+v", CHAIN_ID_BASE, ADDRESSES[CHAIN_ID_BASE], chain.Addresses)
+	}
+}
+
+func TestLoadChainsErrors(t *testing.T) {
+	for name, file := range map[string]struct{ name, content string }{
+		"no chain id":      {"chains.yaml", "- {name: Local}\n"},
+		"short address":    {"chains.yaml", "- {chainId: 31337, mmarket: 0xa2}\n"},
+		"bad asset":        {"chains.json", `[{"chainId": 31337, "assets": [{"symbol": "WETH", "role": "underlying"}]}]`},
+		"malformed json":   {"chains.json", `{"chainId": 31337}`},
+		"yaml given .json": {"chains.json", testRegistryYAML},
+	} {
+		t.Run(name, func(t *testing.T) {
+			restoreRegistry(t)
+			err := LoadChains(writeRegistry(t, file.name, file.content))
+			if err == nil || !strings.Contains(err.Error(), "invalid chain registry") {
+				t.Errorf("LoadChains = %v, want an invalid registry error", err)
+			}
+		})
+	}
+	if err := LoadChains(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadChains succeeded for a missing file")
+	}
+}
+
+func TestChainSpender(t *testing.T) {
+	chain := Chain{ChainID: 31337, Addresses: Addresses{MarginPool: common.HexToAddress("0xa1"), MMarket: common.HexToAddress("0xa2")}}
+	for name, want := range map[string]common.Address{
+		"MMarket":           chain.MMarket,
+		"mmarket":           chain.MMarket,
+		"margin_pool":       chain.MarginPool,
+		"MarginPool":        chain.MarginPool,
+		chain.MMarket.Hex(): chain.MMarket,
+	} {
+		if got, err := chain.Spender(name); err != nil || got != want {
+			t.Errorf("Spender(%s) = %s, %v, want %s", name, got.Hex(), err, want.Hex())
+		}
+	}
+	for _, name := range []string{"strike", common.HexToAddress("0xc1").Hex(), ""} {
+		if _, err := chain.Spender(name); err == nil {
+			t.Errorf("Spender(%q) succeeded", name)
+		}
+	}
+	if _, err := (Chain{ChainID: 31337}).Spender("mmarket"); err == nil {
+		t.Error("Spender(mmarket) succeeded on a chain without MMarket")
+	}
+}
@@ -0,0 +1,122 @@
+package ryskcore
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Nodes only accept a replacement transaction whose fees are at least 10% higher;
+// replacements bump by 12.5% to leave some margin.
+const (
+	feeBumpNumerator   = 1125
+	feeBumpDenominator = 1000
+)
+
+// SpeedUp resubmits the pending transaction with hash with the same nonce, recipient,
+// value and data, but higher fees.
+func (a *Account) SpeedUp(ctx context.Context, chainID int, client Backend, hash common.Hash, txOpts TxOptions) (*TxResult, error) {
+	return a.replace(ctx, chainID, client, hash, txOpts, func(old *types.Transaction) (*common.Address, *big.Int, []byte, uint64) {
+		return old.To(), old.Value(), old.Data(), old.Gas()
+	})
+}
+
+// Cancel replaces the pending transaction with hash by a zero value transfer to the
+// account itself, with the same nonce and higher fees.
+func (a *Account) Cancel(ctx context.Context, chainID int, client Backend, hash common.Hash, txOpts TxOptions) (*TxResult, error) {
+	return a.replace(ctx, chainID, client, hash, txOpts, func(old *types.Transaction) (*common.Address, *big.Int, []byte, uint64) {
+		return &a.Public, new(big.Int), nil, params.TxGas
+	})
+}
+
+func (a *Account) replace(ctx context.Context, chainID int, client Backend, hash common.Hash, txOpts TxOptions, build func(old *types.Transaction) (to *common.Address, value *big.Int, data []byte, gas uint64)) (*TxResult, error) {
+	if err := checkChainID(ctx, client, chainID); err != nil {
+		return nil, err
+	}
+	old, isPending, err := client.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up transaction %s: %w", hash.Hex(), err)
+	}
+	if !isPending {
+		return nil, fmt.Errorf("transaction %s is already mined", hash.Hex())
+	}
+	signer := types.LatestSignerForChainID(big.NewInt(int64(chainID)))
+	from, err := types.Sender(signer, old)
+	if err != nil {
+		return nil, err
+	}
+	if from != a.Public {
+		return nil, fmt.Errorf("transaction %s was sent by %s, not %s", hash.Hex(), from.Hex(), a.Public.Hex())
+	}
+
+	// Current fees, raised to at least the bumped fees of the original transaction.
+	fees := &bind.TransactOpts{}
+	if err := txOpts.setFees(ctx, fees, client); err != nil {
+		return nil, err
+	}
+	to, value, data, gas := build(old)
+
+	var tx *types.Transaction
+	if fees.GasPrice != nil {
+		gasPrice := maxBig(fees.GasPrice, bumpFee(old.GasPrice()))
+		if err := txOpts.checkCap(gasPrice); err != nil {
+			return nil, err
+		}
+		tx = types.NewTx(&types.LegacyTx{
+			Nonce:    old.Nonce(),
+			GasPrice: gasPrice,
+			Gas:      gas,
+			To:       to,
+			Value:    value,
+			Data:     data,
+		})
+	} else {
+		tip := maxBig(fees.GasTipCap, bumpFee(old.GasTipCap()))
+		feeCap := maxBig(fees.GasFeeCap, bumpFee(old.GasFeeCap()), tip)
+		if err := txOpts.checkCap(feeCap); err != nil {
+			return nil, err
+		}
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   big.NewInt(int64(chainID)),
+			Nonce:     old.Nonce(),
+			GasTipCap: tip,
+			GasFeeCap: feeCap,
+			Gas:       gas,
+			To:        to,
+			Value:     value,
+			Data:      data,
+		})
+	}
+
+	if txOpts.DryRun {
+		return a.complete(ctx, client, tx, txOpts)
+	}
+	signed, err := types.SignTx(tx, signer, a.Private)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.SendTransaction(ctx, signed); err != nil {
+		return nil, err
+	}
+	return a.complete(ctx, client, signed, txOpts)
+}
+
+func bumpFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(feeBumpNumerator))
+	return bumped.Div(bumped, big.NewInt(feeBumpDenominator)).Add(bumped, common.Big1)
+}
+
+func maxBig(first *big.Int, rest ...*big.Int) *big.Int {
+	m := first
+	for _, x := range rest {
+		if x.Cmp(m) > 0 {
+			m = x
+		}
+	}
+	return m
+}
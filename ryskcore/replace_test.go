@@ -0,0 +1,129 @@
+package ryskcore
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// pendingApproval sends an approval of 1000 that stays pending, as mining is paused.
+func (c *testChain) pendingApproval(t *testing.T) *types.Transaction {
+	t.Helper()
+	c.pauseMining()
+	ctx := context.Background()
+	result, err := c.account.ApproveSpender(ctx, testChainID, c.client, c.token, c.mmarket, big.NewInt(1000), TxOptions{NoWait: true})
+	if err != nil {
+		t.Fatalf("ApproveSpender: %v", err)
+	}
+	if result.Status != TxStatusPending {
+		t.Fatalf("ApproveSpender result = %+v, want pending", result)
+	}
+	return c.transaction(t, result.Hash)
+}
+
+// transaction returns the transaction with hash, which must be pending.
+func (c *testChain) transaction(t *testing.T, hash common.Hash) *types.Transaction {
+	t.Helper()
+	tx, isPending, err := c.client.TransactionByHash(context.Background(), hash)
+	if err != nil {
+		t.Fatalf("TransactionByHash(%s): %v", hash.Hex(), err)
+	}
+	if !isPending {
+		t.Fatalf("transaction %s is not pending", hash.Hex())
+	}
+	return tx
+}
+
+// checkBumped checks that replacement has the nonce of old and fees at least 12.5%
+// higher.
+func checkBumped(t *testing.T, old, replacement *types.Transaction) {
+	t.Helper()
+	if replacement.Nonce() != old.Nonce() {
+		t.Errorf("replacement nonce = %d, want %d", replacement.Nonce(), old.Nonce())
+	}
+	for _, fee := range []struct {
+		name     string
+		old, new *big.Int
+	}{
+		{"tip", old.GasTipCap(), replacement.GasTipCap()},
+		{"fee cap", old.GasFeeCap(), replacement.GasFeeCap()},
+	} {
+		min := new(big.Int).Div(new(big.Int).Mul(fee.old, big.NewInt(feeBumpNumerator)), big.NewInt(feeBumpDenominator))
+		if fee.new.Cmp(min) < 0 {
+			t.Errorf("replacement %s = %s, want at least %s", fee.name, fee.new, min)
+		}
+	}
+}
+
+func TestSpeedUp(t *testing.T) {
+	t.Parallel()
+	chain := newTestChain(t)
+	ctx := context.Background()
+	old := chain.pendingApproval(t)
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := &Account{Public: crypto.PubkeyToAddress(key.PublicKey), Private: key}
+	if _, err := other.SpeedUp(ctx, testChainID, chain.client, old.Hash(), TxOptions{NoWait: true}); err == nil {
+		t.Error("SpeedUp of another account's transaction succeeded")
+	}
+
+	result, err := chain.account.SpeedUp(ctx, testChainID, chain.client, old.Hash(), TxOptions{NoWait: true})
+	if err != nil {
+		t.Fatalf("SpeedUp: %v", err)
+	}
+	replacement := chain.transaction(t, result.Hash)
+	checkBumped(t, old, replacement)
+	if *replacement.To() != *old.To() || replacement.Value().Cmp(old.Value()) != 0 || string(replacement.Data()) != string(old.Data()) || replacement.Gas() != old.Gas() {
+		t.Errorf("replacement = %+v, want the call of %+v", replacement, old)
+	}
+
+	chain.backend.Commit()
+	status, err := TxStatus(ctx, chain.client, result.Hash)
+	if err != nil || status.Status != TxStatusSuccess {
+		t.Fatalf("TxStatus of the replacement = %+v, %v, want a mined success", status, err)
+	}
+	if got := chain.allowance(t); got.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("allowance = %s, want 1000", got)
+	}
+
+	if _, err := chain.account.SpeedUp(ctx, testChainID, chain.client, result.Hash, TxOptions{NoWait: true}); err == nil {
+		t.Error("SpeedUp of a mined transaction succeeded")
+	}
+}
+
+func TestCancel(t *testing.T) {
+	t.Parallel()
+	chain := newTestChain(t)
+	ctx := context.Background()
+	old := chain.pendingApproval(t)
+
+	result, err := chain.account.Cancel(ctx, testChainID, chain.client, old.Hash(), TxOptions{NoWait: true})
+	if err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	replacement := chain.transaction(t, result.Hash)
+	checkBumped(t, old, replacement)
+	if *replacement.To() != chain.account.Public || replacement.Value().Sign() != 0 || len(replacement.Data()) != 0 || replacement.Gas() != params.TxGas {
+		t.Errorf("replacement = %+v, want an empty transfer to the account", replacement)
+	}
+
+	chain.backend.Commit()
+	status, err := TxStatus(ctx, chain.client, result.Hash)
+	if err != nil || status.Status != TxStatusSuccess {
+		t.Fatalf("TxStatus of the replacement = %+v, %v, want a mined success", status, err)
+	}
+	if _, _, err := chain.client.TransactionByHash(ctx, old.Hash()); err == nil {
+		t.Error("the cancelled transaction was mined")
+	}
+	if got := chain.allowance(t); got.Sign() != 0 {
+		t.Errorf("allowance = %s, want 0", got)
+	}
+}
@@ -0,0 +1,112 @@
+package ryskcore
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/goccy/go-json"
+)
+
+// RFQ is a request for quotes pushed on the /rfqs/<asset> stream. Its fields are
+// named like the matching fields of Quote. A quote answers it with ID as its rfq id.
+type RFQ struct {
+	ID           string  `json:"id"`
+	AssetAddress string  `json:"assetAddress"`
+	ChainID      int     `json:"chainId"`
+	Expiry       int64   `json:"expiry"`
+	IsPut        bool    `json:"isPut"`
+	IsTakerBuy   bool    `json:"isTakerBuy"`
+	Quantity     Uint256 `json:"quantity"`
+	Strike       Uint256 `json:"strike"`
+	// Deadline is when the RFQ stops accepting quotes, as a Unix timestamp.
+	Deadline int64 `json:"deadline"`
+}
+
+// ParseRFQ decodes an RFQ, either on its own or as the params or result of a
+// JSON-RPC message.
+func ParseRFQ(data []byte) (RFQ, error) {
+	var r RFQ
+	if err := unmarshalPayload(data, &r); err != nil {
+		return RFQ{}, fmt.Errorf("invalid rfq: %w", err)
+	}
+	return r, nil
+}
+
+// unmarshalPayload decodes data into v, or its params or result if data is a
+// JSON-RPC message.
+func unmarshalPayload(data []byte, v any) error {
+	var envelope struct {
+		Params json.RawMessage `json:"params"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return err
+	}
+	switch {
+	case len(envelope.Params) > 0 && string(envelope.Params) != "null":
+		data = envelope.Params
+	case len(envelope.Result) > 0 && string(envelope.Result) != "null":
+		data = envelope.Result
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Validate checks that r can be quoted at now: it has an id, a non-zero asset
+// address on a supported chain, a positive quantity and strike, and an
+// expiry in the future. A deadline, if set, must be in the future and before
+// expiry. All problems found are reported together.
+func (r RFQ) Validate(now time.Time) error {
+	var errs []error
+	if r.ID == "" {
+		errs = append(errs, errors.New("id is missing"))
+	}
+	if _, err := GetChain(r.ChainID); err != nil {
+		errs = append(errs, err)
+	}
+	errs = appendErr(errs, checkAddress("assetAddress", r.AssetAddress))
+	errs = appendErr(errs, checkPositive("quantity", r.Quantity))
+	errs = appendErr(errs, checkPositive("strike", r.Strike))
+
+	unix := now.Unix()
+	if r.Expiry <= unix {
+		errs = append(errs, fmt.Errorf("expiry %d is not in the future", r.Expiry))
+	}
+	if r.Deadline != 0 {
+		switch {
+		case r.Deadline <= unix:
+			errs = append(errs, fmt.Errorf("deadline %d has passed", r.Deadline))
+		case r.Deadline >= r.Expiry:
+			errs = append(errs, fmt.Errorf("deadline %d is not before expiry %d", r.Deadline, r.Expiry))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid rfq %s: %w", r.ID, errors.Join(errs...))
+	}
+	return nil
+}
+
+// NewQuote returns an unsigned quote by maker answering r at price with nonce. The
+// asset, chain, expiry, put or call, side, quantity and strike are copied from r.
+// The quote is valid for ttl from now, but not past r's deadline.
+func (r RFQ) NewQuote(maker common.Address, price Uint256, nonce string, ttl time.Duration) Quote {
+	validUntil := time.Now().Add(ttl).Unix()
+	if r.Deadline != 0 && validUntil > r.Deadline {
+		validUntil = r.Deadline
+	}
+	return Quote{
+		AssetAddress: r.AssetAddress,
+		ChainID:      r.ChainID,
+		Expiry:       r.Expiry,
+		IsPut:        r.IsPut,
+		IsTakerBuy:   r.IsTakerBuy,
+		Maker:        maker.Hex(),
+		Nonce:        nonce,
+		Price:        price,
+		Quantity:     r.Quantity,
+		Strike:       r.Strike,
+		ValidUntil:   validUntil,
+	}
+}
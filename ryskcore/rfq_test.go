@@ -0,0 +1,95 @@
+package ryskcore
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testRFQJSON = `{
+	"id": "rfq-1",
+	"assetAddress": "0xb67bfa7b488df4f2efa874f4e59242e9130ae61f",
+	"chainId": 8453,
+	"expiry": 4102444800,
+	"isPut": true,
+	"isTakerBuy": true,
+	"quantity": "1000000000000000000",
+	"strike": "3000000000000000000000",
+	"deadline": 4102444000
+}`
+
+func TestParseRFQ(t *testing.T) {
+	for name, data := range map[string]string{
+		"bare":   testRFQJSON,
+		"params": `{"jsonrpc":"2.0","method":"rfq","params":` + testRFQJSON + `}`,
+		"result": `{"jsonrpc":"2.0","id":"1","result":` + testRFQJSON + `}`,
+	} {
+		r, err := ParseRFQ([]byte(data))
+		if err != nil {
+			t.Fatalf("%s: ParseRFQ: %v", name, err)
+		}
+		if r.ID != "rfq-1" || r.ChainID != CHAIN_ID_BASE || !r.IsPut || !r.IsTakerBuy || r.Strike.String() != "3000000000000000000000" || r.Deadline != 4102444000 {
+			t.Errorf("%s: ParseRFQ = %+v", name, r)
+		}
+	}
+	if _, err := ParseRFQ([]byte(`[]`)); err == nil {
+		t.Error("ParseRFQ([]) succeeded")
+	}
+}
+
+func TestRFQValidate(t *testing.T) {
+	r, err := ParseRFQ([]byte(testRFQJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(r.Deadline-60, 0)
+	if err := r.Validate(now); err != nil {
+		t.Fatalf("Validate = %v", err)
+	}
+
+	for name, tc := range map[string]struct {
+		edit func(*RFQ)
+		want string
+	}{
+		"no id":         {func(r *RFQ) { r.ID = "" }, "id is missing"},
+		"zero quantity": {func(r *RFQ) { r.Quantity = Uint256{} }, "quantity is zero"},
+		"bad asset":     {func(r *RFQ) { r.AssetAddress = "WETH" }, "not a hex address"},
+		"closed":        {func(r *RFQ) { r.Deadline = now.Unix() }, "deadline"},
+		"after expiry":  {func(r *RFQ) { r.Deadline = r.Expiry }, "not before expiry"},
+		"expired":       {func(r *RFQ) { r.Expiry, r.Deadline = now.Unix(), 0 }, "expiry"},
+		"unknown chain": {func(r *RFQ) { r.ChainID = 42 }, "42"},
+	} {
+		r := r
+		tc.edit(&r)
+		if err := r.Validate(now); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: Validate = %v, want an error containing %q", name, err, tc.want)
+		}
+	}
+}
+
+func TestRFQNewQuote(t *testing.T) {
+	r, err := ParseRFQ([]byte(testRFQJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	maker := common.HexToAddress("0x2c7536E3605D9C16a7a3D7b1898e529396a65c23")
+
+	q := r.NewQuote(maker, MustParseUint256("1250000000000000000"), "7", time.Minute)
+	if q.AssetAddress != r.AssetAddress || q.ChainID != r.ChainID || q.Expiry != r.Expiry || q.IsPut != r.IsPut ||
+		q.IsTakerBuy != r.IsTakerBuy || q.Quantity.Cmp(r.Quantity) != 0 || q.Strike.Cmp(r.Strike) != 0 {
+		t.Errorf("NewQuote = %+v, does not match %+v", q, r)
+	}
+	if q.Maker != maker.Hex() || q.Price.String() != "1250000000000000000" || q.Nonce != "7" {
+		t.Errorf("NewQuote = %+v, want maker, price and nonce set", q)
+	}
+	if err := q.Validate(time.Now()); err != nil {
+		t.Errorf("Validate(NewQuote) = %v", err)
+	}
+
+	r.Deadline = time.Now().Add(10 * time.Second).Unix()
+	if q := r.NewQuote(maker, Uint256FromUint64(1), "8", time.Hour); q.ValidUntil != r.Deadline {
+		t.Errorf("NewQuote past the deadline has ValidUntil %d, want %d", q.ValidUntil, r.Deadline)
+	}
+}
@@ -0,0 +1,483 @@
+package ryskcore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/goccy/go-json"
+)
+
+const (
+	// DefaultMaxHeadLag is how many blocks an endpoint may be behind the best head
+	// of the pool and still be used.
+	DefaultMaxHeadLag uint64 = 10
+	// healthCheckTimeout bounds the health check of each endpoint.
+	healthCheckTimeout = 10 * time.Second
+)
+
+// RPCOptions configures an RPCPool.
+type RPCOptions struct {
+	// MaxHeadLag is how many blocks an endpoint may be behind the best head of the
+	// pool and still be used. Defaults to DefaultMaxHeadLag.
+	MaxHeadLag uint64
+	// HTTPClient sends the requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// RPCPool is a set of RPC endpoints of one chain. Its Client sends each request to
+// the first healthy endpoint and fails over to the next one when a request cannot
+// be delivered, or the endpoint answers with a server error or rate limit, either
+// as an HTTP status or as a JSON-RPC error (see isRetryableRPCError).
+type RPCPool struct {
+	chainID   int
+	client    *ethclient.Client
+	http      *http.Client
+	mu        sync.Mutex
+	endpoints []*rpcEndpoint // healthy endpoints, in order of preference
+}
+
+type rpcEndpoint struct {
+	url    *url.URL
+	client *ethclient.Client
+	head   uint64
+}
+
+// DialRPCPool connects to the given endpoints and health checks them: every endpoint
+// that answers must serve chainID, and endpoints that are unreachable or more than
+// MaxHeadLag blocks behind the best head are left out. Failover is only supported
+// between http(s) endpoints; a single websocket endpoint is used as is.
+func DialRPCPool(ctx context.Context, chainID int, urls []string, opts RPCOptions) (*RPCPool, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no rpc url for chain %d", chainID)
+	}
+	if opts.MaxHeadLag == 0 {
+		opts.MaxHeadLag = DefaultMaxHeadLag
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	p := &RPCPool{chainID: chainID, http: opts.HTTPClient}
+
+	if len(urls) == 1 && !isHTTP(urls[0]) {
+		client, err := ethclient.DialContext(ctx, urls[0])
+		if err != nil {
+			return nil, err
+		}
+		if err := checkChainID(ctx, client, chainID); err != nil {
+			client.Close()
+			var mismatch *ChainIDError
+			if errors.As(err, &mismatch) {
+				mismatch.URL = redact(urls[0])
+			}
+			return nil, err
+		}
+		p.client = client
+		return p, nil
+	}
+
+	endpoints := make([]*rpcEndpoint, len(urls))
+	errs := make([]error, len(urls))
+	var wg sync.WaitGroup
+	for i, raw := range urls {
+		if !isHTTP(raw) {
+			return nil, fmt.Errorf("rpc url %s: failover between several endpoints needs http(s) urls", redact(raw))
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			endpoints[i], errs[i] = p.checkEndpoint(ctx, raw)
+		}()
+	}
+	wg.Wait()
+
+	var best uint64
+	var failures []error
+	for i, e := range endpoints {
+		var mismatch *ChainIDError
+		if errors.As(errs[i], &mismatch) {
+			return nil, errs[i]
+		}
+		if errs[i] != nil {
+			failures = append(failures, errs[i])
+			continue
+		}
+		best = max(best, e.head)
+	}
+	for i, e := range endpoints {
+		if errs[i] != nil {
+			continue
+		}
+		if e.head+opts.MaxHeadLag < best {
+			failures = append(failures, fmt.Errorf("rpc url %s is %d blocks behind", e.url.Redacted(), best-e.head))
+			continue
+		}
+		p.endpoints = append(p.endpoints, e)
+	}
+	if len(p.endpoints) == 0 {
+		return nil, fmt.Errorf("no healthy rpc endpoint for chain %d: %w", chainID, errors.Join(failures...))
+	}
+
+	transport := &http.Client{Transport: roundTripFunc(p.roundTrip)}
+	rpcClient, err := rpc.DialOptions(ctx, p.endpoints[0].url.String(), rpc.WithHTTPClient(transport))
+	if err != nil {
+		return nil, err
+	}
+	p.client = ethclient.NewClient(rpcClient)
+	return p, nil
+}
+
+// checkEndpoint dials raw and reads its chain ID and head block.
+func (p *RPCPool) checkEndpoint(ctx context.Context, raw string) (*rpcEndpoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rpc url: %w", err)
+	}
+	rpcClient, err := rpc.DialOptions(ctx, raw, rpc.WithHTTPClient(p.http))
+	if err != nil {
+		return nil, fmt.Errorf("rpc url %s: %w", u.Redacted(), err)
+	}
+	client := ethclient.NewClient(rpcClient)
+	if err := checkChainID(ctx, client, p.chainID); err != nil {
+		client.Close()
+		var mismatch *ChainIDError
+		if errors.As(err, &mismatch) {
+			mismatch.URL = u.Redacted()
+			return nil, mismatch
+		}
+		return nil, fmt.Errorf("rpc url %s: %w", u.Redacted(), err)
+	}
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("rpc url %s: %w", u.Redacted(), err)
+	}
+	return &rpcEndpoint{url: u, client: client, head: head}, nil
+}
+
+// Client returns a client that fails over between the endpoints of the pool.
+func (p *RPCPool) Client() *ethclient.Client {
+	return p.client
+}
+
+// Close closes the connections of the pool.
+func (p *RPCPool) Close() {
+	p.client.Close()
+	for _, e := range p.endpoints {
+		e.client.Close()
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// roundTrip sends req to the first endpoint of the pool and, if it fails, to the
+// others in turn. An endpoint that fails is moved to the back of the pool. If every
+// endpoint fails and one answered with a JSON-RPC error, the last such answer is
+// returned, so that the caller gets the node's error.
+func (p *RPCPool) roundTrip(req *http.Request) (*http.Response, error) {
+	transport := p.http.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+
+	p.mu.Lock()
+	endpoints := append([]*rpcEndpoint(nil), p.endpoints...)
+	p.mu.Unlock()
+
+	var errs []error
+	var rpcErrorResp *http.Response
+	for _, e := range endpoints {
+		attempt := req.Clone(req.Context())
+		attempt.URL = e.url
+		attempt.Host = e.url.Host
+		attempt.Body = io.NopCloser(bytes.NewReader(body))
+		attempt.ContentLength = int64(len(body))
+		attempt.Header.Del("Authorization")
+		if user := e.url.User; user != nil {
+			password, _ := user.Password()
+			attempt.SetBasicAuth(user.Username(), password)
+		}
+
+		resp, err := transport.RoundTrip(attempt)
+		if err == nil {
+			if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+				resp.Body.Close()
+				err = fmt.Errorf("%s", resp.Status)
+			} else if err = checkRPCErrors(resp); err == nil {
+				return resp, nil
+			} else if errors.Is(err, errRetryableRPC) {
+				rpcErrorResp = resp
+			}
+		}
+		if req.Context().Err() != nil {
+			return nil, req.Context().Err()
+		}
+		errs = append(errs, fmt.Errorf("rpc url %s: %w", e.url.Redacted(), err))
+		p.demote(e)
+	}
+	if rpcErrorResp != nil {
+		return rpcErrorResp, nil
+	}
+	return nil, errors.Join(errs...)
+}
+
+// errRetryableRPC wraps JSON-RPC errors that another endpoint may not return.
+var errRetryableRPC = errors.New("retryable json-rpc error")
+
+// rpcResponse is the error part of a JSON-RPC response.
+type rpcResponse struct {
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// checkRPCErrors reads the body of a successful HTTP response and returns an error
+// wrapping errRetryableRPC if it holds a JSON-RPC error, or one response of a batch
+// with an error, that isRetryableRPCError accepts. The body is replaced so that it
+// can still be read.
+func checkRPCErrors(resp *http.Response) error {
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	if !bytes.Contains(data, []byte(`"error"`)) {
+		return nil
+	}
+
+	var responses []rpcResponse
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &responses); err != nil {
+			return nil
+		}
+	} else {
+		var single rpcResponse
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return nil
+		}
+		responses = append(responses, single)
+	}
+	for _, r := range responses {
+		if r.Error != nil && isRetryableRPCError(r.Error.Code, r.Error.Message) {
+			return fmt.Errorf("%w %d: %s", errRetryableRPC, r.Error.Code, r.Error.Message)
+		}
+	}
+	return nil
+}
+
+// retryableRPCMessages are parts of JSON-RPC error messages of endpoints that are
+// overloaded, rate limited or missing recent blocks, whatever the error code.
+var retryableRPCMessages = []string{
+	"header not found",
+	"unknown block",
+	"rate limit",
+	"too many requests",
+	"limit exceeded",
+	"internal error",
+}
+
+// isRetryableRPCError reports whether a JSON-RPC error is a problem of the endpoint
+// rather than of the request, so that another endpoint may succeed: a rate limit
+// (-32005 or 429), an internal error (-32603), or a retryableRPCMessages message.
+// Errors such as reverts or nonce too low are returned as is.
+func isRetryableRPCError(code int, message string) bool {
+	switch code {
+	case -32005, -32603, http.StatusTooManyRequests:
+		return true
+	}
+	message = strings.ToLower(message)
+	for _, m := range retryableRPCMessages {
+		if strings.Contains(message, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// demote moves e to the back of the pool.
+func (p *RPCPool) demote(e *rpcEndpoint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, x := range p.endpoints {
+		if x == e {
+			p.endpoints = append(append(p.endpoints[:i:i], p.endpoints[i+1:]...), e)
+			return
+		}
+	}
+}
+
+// Quorum returns a reader whose calls and balance reads are sent to every endpoint
+// of the pool and only succeed if at least n endpoints return the same result. All
+// reads are made at the same block: the lowest head among the endpoints, so that
+// every endpoint has it.
+func (p *RPCPool) Quorum(ctx context.Context, n int) (*QuorumReader, error) {
+	p.mu.Lock()
+	endpoints := append([]*rpcEndpoint(nil), p.endpoints...)
+	p.mu.Unlock()
+	if n < 1 || n > len(endpoints) {
+		return nil, fmt.Errorf("quorum of %d needs between 1 and %d healthy endpoints", n, len(endpoints))
+	}
+
+	var block uint64
+	for i, e := range endpoints {
+		head, err := e.client.BlockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("rpc url %s: %w", e.url.Redacted(), err)
+		}
+		if i == 0 || head < block {
+			block = head
+		}
+	}
+	return &QuorumReader{endpoints: endpoints, n: n, block: new(big.Int).SetUint64(block)}, nil
+}
+
+// QuorumReader reads contract state and balances from several endpoints and
+// requires them to agree. It satisfies WalletBackend.
+type QuorumReader struct {
+	endpoints []*rpcEndpoint
+	n         int
+	block     *big.Int
+}
+
+// CodeAt returns the code of account, as agreed by the quorum.
+func (q *QuorumReader) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return quorumRead(ctx, q, func(c *ethclient.Client) ([]byte, error) {
+		return c.CodeAt(ctx, account, q.at(blockNumber))
+	})
+}
+
+// CallContract executes msg, and returns its result as agreed by the quorum.
+func (q *QuorumReader) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return quorumRead(ctx, q, func(c *ethclient.Client) ([]byte, error) {
+		return c.CallContract(ctx, msg, q.at(blockNumber))
+	})
+}
+
+// BalanceAt returns the native balance of account, as agreed by the quorum.
+func (q *QuorumReader) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	b, err := quorumRead(ctx, q, func(c *ethclient.Client) ([]byte, error) {
+		balance, err := c.BalanceAt(ctx, account, q.at(blockNumber))
+		if err != nil {
+			return nil, err
+		}
+		return balance.Bytes(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// at returns blockNumber, or the block of the reader if it is nil.
+func (q *QuorumReader) at(blockNumber *big.Int) *big.Int {
+	if blockNumber == nil {
+		return q.block
+	}
+	return blockNumber
+}
+
+// quorumRead runs read against every endpoint of q and returns the first result that
+// at least q.n endpoints agree on.
+func quorumRead(ctx context.Context, q *QuorumReader, read func(*ethclient.Client) ([]byte, error)) ([]byte, error) {
+	results := make([][]byte, len(q.endpoints))
+	errs := make([]error, len(q.endpoints))
+	var wg sync.WaitGroup
+	for i, e := range q.endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = read(e.client)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("rpc url %s: %w", e.url.Redacted(), errs[i])
+			}
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	for i, r := range results {
+		if errs[i] != nil {
+			continue
+		}
+		agree := 0
+		for j := range results {
+			if errs[j] == nil && bytes.Equal(r, results[j]) {
+				agree++
+			}
+		}
+		if agree >= q.n {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("fewer than %d of %d rpc endpoints agree on the result at block %s: %w", q.n, len(q.endpoints), q.block, errors.Join(errs...))
+}
+
+// ChainIDError is returned when a node serves a different chain than expected.
+type ChainIDError struct {
+	URL      string
+	Expected int
+	Actual   *big.Int
+}
+
+func (e *ChainIDError) Error() string {
+	if e.URL != "" {
+		return fmt.Sprintf("rpc url %s serves chain %s, expected chain %d", e.URL, e.Actual, e.Expected)
+	}
+	return fmt.Sprintf("node serves chain %s, expected chain %d", e.Actual, e.Expected)
+}
+
+// checkChainID returns a *ChainIDError if client does not serve chainID.
+func checkChainID(ctx context.Context, client ethereum.ChainIDReader, chainID int) error {
+	actual, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read chain id: %w", err)
+	}
+	if !actual.IsInt64() || actual.Int64() != int64(chainID) {
+		return &ChainIDError{Expected: chainID, Actual: actual}
+	}
+	return nil
+}
+
+func redact(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return u.Redacted()
+}
+
+func isHTTP(raw string) bool {
+	lower := strings.ToLower(raw)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
@@ -0,0 +1,287 @@
+package ryskcore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/goccy/go-json"
+)
+
+// fakeNode is a JSON-RPC endpoint serving eth_chainId, eth_blockNumber and
+// eth_getBalance. Balance requests fail with status or rpcError when they are set.
+type fakeNode struct {
+	chainID uint64
+	head    uint64
+	balance int64
+
+	mu       sync.Mutex
+	status   int
+	rpcError *rpcErrorBody
+	balances int      // number of balance requests
+	blocks   []string // block argument of each balance request
+}
+
+type rpcErrorBody struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func newFakeNode(t *testing.T, head uint64, balance int64) (*fakeNode, string) {
+	t.Helper()
+	n := &fakeNode{chainID: testChainID, head: head, balance: balance}
+	server := httptest.NewServer(n)
+	t.Cleanup(server.Close)
+	return n, server.URL
+}
+
+func (n *fakeNode) fail(status int, rpcError *rpcErrorBody) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.status, n.rpcError = status, rpcError
+}
+
+func (n *fakeNode) balanceRequests() (int, []string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.balances, append([]string(nil), n.blocks...)
+}
+
+func (n *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+	switch req.Method {
+	case "eth_chainId":
+		resp["result"] = hexutil.Uint64(n.chainID)
+	case "eth_blockNumber":
+		resp["result"] = hexutil.Uint64(n.head)
+	case "eth_getBalance":
+		n.mu.Lock()
+		n.balances++
+		var block string
+		if len(req.Params) > 1 {
+			json.Unmarshal(req.Params[1], &block)
+		}
+		n.blocks = append(n.blocks, block)
+		status, rpcError := n.status, n.rpcError
+		n.mu.Unlock()
+		if status != 0 {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		if rpcError != nil {
+			resp["error"] = rpcError
+		} else {
+			resp["result"] = (*hexutil.Big)(big.NewInt(n.balance))
+		}
+	default:
+		resp["error"] = rpcErrorBody{Code: -32601, Message: "method not found"}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func TestDialRPCPoolHealthCheck(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	_, healthy := newFakeNode(t, 100, 1)
+	_, near := newFakeNode(t, 95, 1)
+	_, behind := newFakeNode(t, 80, 1)
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	p, err := DialRPCPool(ctx, testChainID, []string{down.URL, behind, near, healthy}, RPCOptions{})
+	if err != nil {
+		t.Fatalf("DialRPCPool: %v", err)
+	}
+	defer p.Close()
+	if got := endpointURLs(p); strings.Join(got, " ") != near+" "+healthy {
+		t.Errorf("endpoints = %v, want the two within the head lag, in order", got)
+	}
+
+	otherChain, wrong := newFakeNode(t, 100, 1)
+	otherChain.chainID = 1
+	var mismatch *ChainIDError
+	if _, err := DialRPCPool(ctx, testChainID, []string{healthy, wrong}, RPCOptions{}); !errors.As(err, &mismatch) {
+		t.Errorf("DialRPCPool with an endpoint of another chain = %v, want a ChainIDError", err)
+	}
+	if _, err := DialRPCPool(ctx, testChainID, []string{down.URL}, RPCOptions{}); err == nil {
+		t.Error("DialRPCPool without a healthy endpoint succeeded")
+	}
+}
+
+func endpointURLs(p *RPCPool) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	urls := make([]string, len(p.endpoints))
+	for i, e := range p.endpoints {
+		urls[i] = e.url.String()
+	}
+	return urls
+}
+
+func TestRPCPoolFailover(t *testing.T) {
+	t.Parallel()
+	for _, tt := range []struct {
+		name     string
+		status   int
+		rpcError *rpcErrorBody
+		failover bool
+	}{
+		{name: "server error", status: http.StatusServiceUnavailable, failover: true},
+		{name: "http rate limit", status: http.StatusTooManyRequests, failover: true},
+		{name: "json-rpc limit exceeded", rpcError: &rpcErrorBody{-32005, "limit exceeded"}, failover: true},
+		{name: "json-rpc internal error", rpcError: &rpcErrorBody{-32603, "internal error"}, failover: true},
+		{name: "json-rpc header not found", rpcError: &rpcErrorBody{-32000, "header not found"}, failover: true},
+		{name: "json-rpc rate limit code", rpcError: &rpcErrorBody{429, "Too Many Requests"}, failover: true},
+		{name: "json-rpc revert", rpcError: &rpcErrorBody{3, "execution reverted"}},
+		{name: "json-rpc invalid params", rpcError: &rpcErrorBody{-32602, "invalid argument 0"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			first, firstURL := newFakeNode(t, 100, 1)
+			second, secondURL := newFakeNode(t, 100, 2)
+			p, err := DialRPCPool(context.Background(), testChainID, []string{firstURL, secondURL}, RPCOptions{})
+			if err != nil {
+				t.Fatalf("DialRPCPool: %v", err)
+			}
+			defer p.Close()
+			first.fail(tt.status, tt.rpcError)
+
+			balance, err := p.Client().BalanceAt(context.Background(), testAccount, nil)
+			secondCalls, _ := second.balanceRequests()
+			if !tt.failover {
+				if err == nil || !strings.Contains(err.Error(), tt.rpcError.Message) {
+					t.Errorf("BalanceAt = %v, %v, want the first endpoint's error", balance, err)
+				}
+				if secondCalls != 0 || endpointURLs(p)[0] != firstURL {
+					t.Errorf("the request failed over on a %q error", tt.rpcError.Message)
+				}
+				return
+			}
+			if err != nil || balance.Int64() != 2 {
+				t.Fatalf("BalanceAt = %v, %v, want the second endpoint's balance 2", balance, err)
+			}
+			if got := endpointURLs(p); got[0] != secondURL || got[1] != firstURL {
+				t.Errorf("endpoints = %v, want the failing one demoted", got)
+			}
+
+			// The demoted endpoint is only tried again if the other one fails.
+			first.fail(0, nil)
+			if _, err := p.Client().BalanceAt(context.Background(), testAccount, nil); err != nil {
+				t.Fatalf("BalanceAt: %v", err)
+			}
+			if firstCalls, _ := first.balanceRequests(); firstCalls != 1 {
+				t.Errorf("demoted endpoint got %d balance requests, want 1", firstCalls)
+			}
+		})
+	}
+}
+
+func TestRPCPoolAllEndpointsFail(t *testing.T) {
+	t.Parallel()
+	first, firstURL := newFakeNode(t, 100, 1)
+	second, secondURL := newFakeNode(t, 100, 2)
+	p, err := DialRPCPool(context.Background(), testChainID, []string{firstURL, secondURL}, RPCOptions{})
+	if err != nil {
+		t.Fatalf("DialRPCPool: %v", err)
+	}
+	defer p.Close()
+
+	first.fail(http.StatusBadGateway, nil)
+	second.fail(0, &rpcErrorBody{-32005, "daily request limit exceeded"})
+	if _, err := p.Client().BalanceAt(context.Background(), testAccount, nil); err == nil || !strings.Contains(err.Error(), "daily request limit exceeded") {
+		t.Errorf("BalanceAt = %v, want the JSON-RPC error of the last endpoint", err)
+	}
+
+	second.fail(http.StatusServiceUnavailable, nil)
+	if _, err := p.Client().BalanceAt(context.Background(), testAccount, nil); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("BalanceAt = %v, want the status of the endpoints", err)
+	}
+}
+
+func TestCheckRPCErrorsBatch(t *testing.T) {
+	t.Parallel()
+	for body, retryable := range map[string]bool{
+		`{"jsonrpc":"2.0","id":1,"result":"0x1"}`: false,
+		`[{"jsonrpc":"2.0","id":1,"result":"0x1"},{"jsonrpc":"2.0","id":2,"error":{"code":-32005,"message":"slow"}}]`: true,
+		`[{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted"}}]`:                                false,
+		`{"jsonrpc":"2.0","id":1,"result":{"error":"a field called error"}}`:                                          false,
+		`not json "error"`: false,
+	} {
+		resp := &http.Response{Body: io.NopCloser(strings.NewReader(body))}
+		err := checkRPCErrors(resp)
+		if got := errors.Is(err, errRetryableRPC); got != retryable {
+			t.Errorf("checkRPCErrors(%s) = %v, want retryable %t", body, err, retryable)
+		}
+		if data, _ := io.ReadAll(resp.Body); string(data) != body {
+			t.Errorf("body after checkRPCErrors = %q, want %q", data, body)
+		}
+	}
+}
+
+func TestQuorum(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	var nodes []*fakeNode
+	var urls []string
+	for _, n := range []struct {
+		head    uint64
+		balance int64
+	}{{100, 5}, {98, 5}, {100, 6}} {
+		node, url := newFakeNode(t, n.head, n.balance)
+		nodes, urls = append(nodes, node), append(urls, url)
+	}
+	p, err := DialRPCPool(ctx, testChainID, urls, RPCOptions{})
+	if err != nil {
+		t.Fatalf("DialRPCPool: %v", err)
+	}
+	defer p.Close()
+
+	q, err := p.Quorum(ctx, 2)
+	if err != nil {
+		t.Fatalf("Quorum: %v", err)
+	}
+	if balance, err := q.BalanceAt(ctx, testAccount, nil); err != nil || balance.Int64() != 5 {
+		t.Errorf("BalanceAt with a quorum of 2 = %v, %v, want 5", balance, err)
+	}
+	for i, node := range nodes {
+		if _, blocks := node.balanceRequests(); len(blocks) != 1 || blocks[0] != "0x62" {
+			t.Errorf("endpoint %d was read at %v, want the lowest head 0x62", i, blocks)
+		}
+	}
+
+	q, err = p.Quorum(ctx, 3)
+	if err != nil {
+		t.Fatalf("Quorum: %v", err)
+	}
+	if _, err := q.BalanceAt(ctx, testAccount, nil); err == nil || !strings.Contains(err.Error(), "fewer than 3 of 3") {
+		t.Errorf("BalanceAt with a quorum of 3 = %v, want a disagreement", err)
+	}
+
+	nodes[2].fail(0, &rpcErrorBody{-32000, "missing trie node"})
+	if balance, err := q.BalanceAt(ctx, testAccount, nil); err == nil {
+		t.Errorf("BalanceAt with a quorum of 3 and a failing endpoint = %v, want an error", balance)
+	}
+
+	for _, n := range []int{0, 4} {
+		if _, err := p.Quorum(ctx, n); err == nil {
+			t.Errorf("Quorum(%d) of 3 endpoints succeeded", n)
+		}
+	}
+}
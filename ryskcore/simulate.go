@@ -0,0 +1,163 @@
+package ryskcore
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// callABIs are the contracts whose calls a Simulation decodes.
+var callABIs = []*bind.MetaData{
+	IERC20MetaData,
+}
+
+// SimulationBackend is what a dry run needs from a node. Every Backend is one.
+type SimulationBackend interface {
+	ethereum.PendingContractCaller
+	ethereum.GasEstimator
+}
+
+// pendingGasEstimator is implemented by backends that can estimate gas against a
+// chosen block, such as ethclient.Client.
+type pendingGasEstimator interface {
+	EstimateGasAtBlock(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) (uint64, error)
+}
+
+// Simulation describes a transaction that was built but not sent: the decoded call,
+// what it returns against the pending block, and what sending it would cost.
+type Simulation struct {
+	From           common.Address  `json:"from"`
+	To             *common.Address `json:"to"`
+	Method         string          `json:"method,omitempty"`
+	Args           map[string]any  `json:"args,omitempty"`
+	Data           hexutil.Bytes   `json:"data"`
+	Nonce          uint64          `json:"nonce"`
+	Return         []any           `json:"return,omitempty"`
+	Status         string          `json:"status"`
+	RevertReason   string          `json:"revertReason,omitempty"`
+	Gas            uint64          `json:"gas,omitempty"`
+	GasPrice       *big.Int        `json:"gasPrice,omitempty"`
+	MaxFee         *big.Int        `json:"maxFee,omitempty"`
+	MaxPriorityFee *big.Int        `json:"maxPriorityFee,omitempty"`
+	// MaxCost is the most the transaction could cost in wei: Gas times the gas price
+	// or max fee.
+	MaxCost *big.Int `json:"maxCost,omitempty"`
+}
+
+// Simulate calls tx, which need not be signed, from from against the pending block
+// and estimates its gas. A revert is returned with its result as a *RevertError.
+func Simulate(ctx context.Context, backend SimulationBackend, from common.Address, tx *types.Transaction) (*TxResult, error) {
+	sim := &Simulation{
+		From:  from,
+		To:    tx.To(),
+		Data:  tx.Data(),
+		Nonce: tx.Nonce(),
+	}
+	if tx.Type() == types.LegacyTxType {
+		sim.GasPrice = tx.GasPrice()
+	} else {
+		sim.MaxFee = tx.GasFeeCap()
+		sim.MaxPriorityFee = tx.GasTipCap()
+	}
+	method := decodeCall(sim)
+
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	result := &TxResult{Simulation: sim}
+	ret, err := backend.PendingCallContract(ctx, msg)
+	if err == nil {
+		sim.Gas, err = estimatePendingGas(ctx, backend, msg)
+	}
+	if err != nil {
+		sim.Status = TxStatusReverted
+		sim.RevertReason = errorReason(err)
+		result.Status, result.RevertReason = sim.Status, sim.RevertReason
+		return result, &RevertError{Result: result}
+	}
+
+	sim.Status = TxStatusSimulated
+	result.Status = sim.Status
+	if method != nil {
+		if out, err := method.Outputs.Unpack(ret); err == nil {
+			sim.Return = jsonValues(out)
+		}
+	}
+	feePerGas := sim.GasPrice
+	if feePerGas == nil {
+		feePerGas = sim.MaxFee
+	}
+	sim.MaxCost = new(big.Int).Mul(new(big.Int).SetUint64(sim.Gas), feePerGas)
+	return result, nil
+}
+
+func estimatePendingGas(ctx context.Context, backend SimulationBackend, msg ethereum.CallMsg) (uint64, error) {
+	if est, ok := backend.(pendingGasEstimator); ok {
+		return est.EstimateGasAtBlock(ctx, msg, big.NewInt(int64(rpc.PendingBlockNumber)))
+	}
+	return backend.EstimateGas(ctx, msg)
+}
+
+// decodeCall fills in the method and arguments of sim from its calldata, if it calls
+// a method of one of callABIs, and returns that method.
+func decodeCall(sim *Simulation) *abi.Method {
+	if len(sim.Data) < 4 {
+		return nil
+	}
+	for _, meta := range callABIs {
+		parsed, err := meta.GetAbi()
+		if err != nil {
+			continue
+		}
+		method, err := parsed.MethodById(sim.Data[:4])
+		if err != nil {
+			continue
+		}
+		args := make(map[string]any)
+		if err := method.Inputs.UnpackIntoMap(args, sim.Data[4:]); err != nil {
+			return nil
+		}
+		for name, v := range args {
+			args[name] = jsonValue(v)
+		}
+		sim.Method, sim.Args = method.Sig, args
+		return method
+	}
+	return nil
+}
+
+func jsonValues(vs []any) []any {
+	for i, v := range vs {
+		vs[i] = jsonValue(v)
+	}
+	return vs
+}
+
+// jsonValue turns byte slices and fixed size byte arrays, such as bytes32, into hex
+// strings rather than arrays of numbers.
+func jsonValue(v any) any {
+	if _, ok := v.(common.Address); ok {
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return hexutil.Bytes(b)
+	}
+	if b, ok := v.([]byte); ok {
+		return hexutil.Bytes(b)
+	}
+	return v
+}
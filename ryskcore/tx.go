@@ -0,0 +1,312 @@
+package ryskcore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// TxOptions controls how transactions sent by an Account are built.
+// The zero value estimates everything from the node.
+type TxOptions struct {
+	// MaxFee is the max fee per gas of dynamic fee transactions, or the gas price of
+	// legacy ones. Defaults to twice the latest base fee plus the priority fee.
+	MaxFee *big.Int
+	// MaxPriorityFee is the priority fee per gas of dynamic fee transactions.
+	// Defaults to the node's suggested tip. Chains without EIP-1559 reject it.
+	MaxPriorityFee *big.Int
+	// GasPriceCap refuses to send a transaction whose fee per gas could exceed it.
+	GasPriceCap *big.Int
+	// Nonces assigns nonces to transactions. Defaults to the node's pending nonce.
+	Nonces *NonceManager
+	// NoWait returns as soon as the transaction is sent, with a pending result.
+	NoWait bool
+	// Timeout bounds the wait for the transaction to be mined. Zero waits until the
+	// context is done.
+	Timeout time.Duration
+	// DryRun builds the transaction and simulates it against the pending block
+	// instead of signing and sending it. The result carries the Simulation.
+	DryRun bool
+}
+
+// transact builds transaction options and calls send with them. With a NonceManager,
+// a nonce the node rejects as too low resyncs the manager and is retried once, and
+// the nonce of a transaction that could not be sent is released.
+//
+// On a dry run, send gets options that make the binding return the transaction
+// unsigned and unsent, and no nonce is taken from the NonceManager.
+func (a *Account) transact(ctx context.Context, chainID int, client Backend, txOpts TxOptions, send func(*bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, error) {
+	if txOpts.DryRun {
+		opts, err := a.dryRunOpts(ctx, client, txOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create transaction options: %w", err)
+		}
+		return send(opts)
+	}
+	for attempt := 0; ; attempt++ {
+		opts, err := a.newTransactionOpts(ctx, chainID, client, txOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create transaction options: %w", err)
+		}
+		tx, err := send(opts)
+		if err == nil || txOpts.Nonces == nil {
+			return tx, estimateRevert(err)
+		}
+		if !isNonceTooLow(err) {
+			txOpts.Nonces.Release(chainID, a.Public, opts.Nonce.Uint64())
+			return nil, estimateRevert(err)
+		}
+		if rerr := txOpts.Nonces.Reset(chainID, a.Public); rerr != nil || attempt > 0 {
+			return nil, err
+		}
+	}
+}
+
+// dryRunOpts returns transaction options for a dry run. The gas limit is only a
+// placeholder that stops the binding from estimating gas itself: Simulate does that,
+// and reports a revert instead of failing.
+func (a *Account) dryRunOpts(ctx context.Context, client Backend, txOpts TxOptions) (*bind.TransactOpts, error) {
+	opts := &bind.TransactOpts{
+		From:     a.Public,
+		Context:  ctx,
+		GasLimit: params.MaxGasLimit,
+		NoSend:   true,
+		Signer: func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return tx, nil
+		},
+	}
+	if err := txOpts.setFees(ctx, opts, client); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// setFees fills in the fee fields of opts. Dynamic fee transactions are used unless
+// the latest block has no base fee, i.e. the chain does not support EIP-1559.
+func (o TxOptions) setFees(ctx context.Context, opts *bind.TransactOpts, client Backend) error {
+	for _, fee := range []struct {
+		name  string
+		value *big.Int
+	}{{"max fee", o.MaxFee}, {"priority fee", o.MaxPriorityFee}, {"gas price cap", o.GasPriceCap}} {
+		if fee.value != nil && fee.value.Sign() < 0 {
+			return fmt.Errorf("%s %s is negative", fee.name, fee.value)
+		}
+	}
+
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if head.BaseFee == nil {
+		if o.MaxPriorityFee != nil {
+			return errors.New("the chain has no EIP-1559 base fee, so it takes a gas price (the max fee) but no priority fee")
+		}
+		gasPrice := o.MaxFee
+		if gasPrice == nil {
+			if gasPrice, err = client.SuggestGasPrice(ctx); err != nil {
+				return err
+			}
+		}
+		if err := o.checkCap(gasPrice); err != nil {
+			return err
+		}
+		opts.GasPrice = gasPrice
+		return nil
+	}
+
+	tip := o.MaxPriorityFee
+	if tip == nil {
+		if tip, err = client.SuggestGasTipCap(ctx); err != nil {
+			return err
+		}
+	}
+	maxFee := o.MaxFee
+	if maxFee == nil {
+		maxFee = new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), tip)
+	}
+	if maxFee.Cmp(tip) < 0 {
+		return fmt.Errorf("max fee %s is below the priority fee %s", maxFee, tip)
+	}
+	if err := o.checkCap(maxFee); err != nil {
+		return err
+	}
+	opts.GasTipCap = tip
+	opts.GasFeeCap = maxFee
+	return nil
+}
+
+func (o TxOptions) checkCap(feePerGas *big.Int) error {
+	if o.GasPriceCap != nil && feePerGas.Cmp(o.GasPriceCap) > 0 {
+		return fmt.Errorf("fee per gas %s wei exceeds the gas price cap of %s wei", feePerGas, o.GasPriceCap)
+	}
+	return nil
+}
+
+// TxResult is the outcome of a transaction. Only Hash and Status are set while it is
+// pending. A dry run has no Hash; its Status, RevertReason and Simulation are set.
+// Neither has a transaction that was not sent because it reverted when its gas was
+// estimated; only its Status and RevertReason are set.
+type TxResult struct {
+	Hash              common.Hash `json:"hash"`
+	BlockNumber       *big.Int    `json:"blockNumber,omitempty"`
+	GasUsed           uint64      `json:"gasUsed,omitempty"`
+	EffectiveGasPrice *big.Int    `json:"effectiveGasPrice,omitempty"`
+	Status            string      `json:"status"`
+	RevertReason      string      `json:"revertReason,omitempty"`
+	Simulation        *Simulation `json:"simulation,omitempty"`
+}
+
+const (
+	TxStatusPending   = "pending"
+	TxStatusSuccess   = "success"
+	TxStatusReverted  = "reverted"
+	TxStatusSimulated = "simulated"
+)
+
+// RevertError is returned when a transaction was mined but reverted, reverts in a dry
+// run, or reverts when its gas is estimated, in which case it is not sent.
+type RevertError struct {
+	Result *TxResult
+}
+
+func (e *RevertError) Error() string {
+	if e.Result.Simulation != nil {
+		if e.Result.RevertReason != "" {
+			return "simulated transaction reverted: " + e.Result.RevertReason
+		}
+		return "simulated transaction reverted"
+	}
+	if e.Result.Hash == (common.Hash{}) {
+		if e.Result.RevertReason != "" {
+			return "transaction not sent, it reverts: " + e.Result.RevertReason
+		}
+		return "transaction not sent, it reverts"
+	}
+	if e.Result.RevertReason != "" {
+		return fmt.Sprintf("transaction %s reverted: %s", e.Result.Hash.Hex(), e.Result.RevertReason)
+	}
+	return fmt.Sprintf("transaction %s reverted", e.Result.Hash.Hex())
+}
+
+// complete finishes sending tx as configured by txOpts: a dry run simulates the
+// unsigned tx, otherwise it waits for tx to be mined unless NoWait is set.
+// A reverted transaction returns its result together with a *RevertError, and one
+// still pending when the timeout expires returns a pending result and an error.
+func (a *Account) complete(ctx context.Context, client Backend, tx *types.Transaction, txOpts TxOptions) (*TxResult, error) {
+	if txOpts.DryRun {
+		return Simulate(ctx, client, a.Public, tx)
+	}
+
+	pending := &TxResult{Hash: tx.Hash(), Status: TxStatusPending}
+	if txOpts.NoWait {
+		return pending, nil
+	}
+
+	waitCtx := ctx
+	if txOpts.Timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, txOpts.Timeout)
+		defer cancel()
+	}
+	rx, err := bind.WaitMined(waitCtx, client, tx)
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			return pending, fmt.Errorf("transaction %s not mined after %s", tx.Hash().Hex(), txOpts.Timeout)
+		}
+		return nil, err
+	}
+	return receiptResult(ctx, client, tx, rx, a.Public)
+}
+
+// TxStatus looks up the transaction with hash and returns its result, which is
+// pending if it has not been mined yet.
+func TxStatus(ctx context.Context, client Backend, hash common.Hash) (*TxResult, error) {
+	tx, isPending, err := client.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up transaction %s: %w", hash.Hex(), err)
+	}
+	if isPending {
+		return &TxResult{Hash: hash, Status: TxStatusPending}, nil
+	}
+	rx, err := client.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt of %s: %w", hash.Hex(), err)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, err
+	}
+	return receiptResult(ctx, client, tx, rx, from)
+}
+
+func receiptResult(ctx context.Context, client Backend, tx *types.Transaction, rx *types.Receipt, from common.Address) (*TxResult, error) {
+	result := &TxResult{
+		Hash:              rx.TxHash,
+		BlockNumber:       rx.BlockNumber,
+		GasUsed:           rx.GasUsed,
+		EffectiveGasPrice: rx.EffectiveGasPrice,
+		Status:            TxStatusSuccess,
+	}
+	if rx.Status == types.ReceiptStatusSuccessful {
+		return result, nil
+	}
+
+	result.Status = TxStatusReverted
+	result.RevertReason = revertReason(ctx, client, tx, from, rx.BlockNumber)
+	return result, &RevertError{Result: result}
+}
+
+// revertReason replays tx on top of the block before it was mined and decodes the
+// revert reason. It returns an empty string if no reason can be recovered.
+func revertReason(ctx context.Context, client Backend, tx *types.Transaction, from common.Address, blockNumber *big.Int) string {
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	_, err := client.CallContract(ctx, msg, new(big.Int).Sub(blockNumber, big.NewInt(1)))
+	if err == nil {
+		return ""
+	}
+	return errorReason(err)
+}
+
+// executionReverted is how nodes report a call or gas estimate that reverted.
+const executionReverted = "execution reverted"
+
+// estimateRevert returns a *RevertError for err if it reports that the transaction
+// reverted while its gas was being estimated, and err otherwise.
+func estimateRevert(err error) error {
+	if err == nil || !strings.Contains(err.Error(), executionReverted) {
+		return err
+	}
+	return &RevertError{Result: &TxResult{Status: TxStatusReverted, RevertReason: errorReason(err)}}
+}
+
+// errorReason decodes the revert reason carried by a call error, falling back to
+// the error message.
+func errorReason(err error) string {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok {
+			if reason, uerr := abi.UnpackRevert(common.FromHex(data)); uerr == nil {
+				return reason
+			}
+		}
+	}
+	return err.Error()
+}
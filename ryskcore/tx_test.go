@@ -0,0 +1,141 @@
+package ryskcore
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// checkRevert checks that err is a *RevertError for a reverted transaction with
+// reason, and returns its result.
+func checkRevert(t *testing.T, err error, reason string) *TxResult {
+	t.Helper()
+	var reverted *RevertError
+	if !errors.As(err, &reverted) {
+		t.Fatalf("err = %v, want a *RevertError", err)
+	}
+	if reverted.Result.Status != TxStatusReverted || reverted.Result.RevertReason != reason {
+		t.Errorf("RevertError result = %+v, want reverted with reason %q", reverted.Result, reason)
+	}
+	return reverted.Result
+}
+
+// feeBackend is a Backend whose latest block has baseFee, nil for a legacy chain,
+// and which suggests a gas price of 30 and a tip of 2. Other methods are not
+// implemented.
+type feeBackend struct {
+	Backend
+	baseFee *big.Int
+}
+
+func (b feeBackend) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return &types.Header{BaseFee: b.baseFee}, nil
+}
+
+func (b feeBackend) SuggestGasPrice(context.Context) (*big.Int, error) {
+	return big.NewInt(30), nil
+}
+
+func (b feeBackend) SuggestGasTipCap(context.Context) (*big.Int, error) {
+	return big.NewInt(2), nil
+}
+
+func TestSetFees(t *testing.T) {
+	t.Parallel()
+	for _, tt := range []struct {
+		name                     string
+		baseFee                  *big.Int
+		opts                     TxOptions
+		gasPrice, tipCap, feeCap int64
+		err                      string
+	}{
+		{name: "dynamic defaults", baseFee: big.NewInt(10), tipCap: 2, feeCap: 22},
+		{name: "dynamic flags", baseFee: big.NewInt(10), opts: TxOptions{MaxFee: big.NewInt(50), MaxPriorityFee: big.NewInt(5)}, tipCap: 5, feeCap: 50},
+		{name: "max fee below tip", baseFee: big.NewInt(10), opts: TxOptions{MaxFee: big.NewInt(1)}, err: "below the priority fee"},
+		{name: "dynamic over cap", baseFee: big.NewInt(10), opts: TxOptions{GasPriceCap: big.NewInt(21)}, err: "exceeds the gas price cap"},
+		{name: "legacy default", gasPrice: 30},
+		{name: "legacy max fee", opts: TxOptions{MaxFee: big.NewInt(40)}, gasPrice: 40},
+		{name: "legacy priority fee", opts: TxOptions{MaxPriorityFee: big.NewInt(1)}, err: "no priority fee"},
+		{name: "legacy over cap", opts: TxOptions{GasPriceCap: big.NewInt(29)}, err: "exceeds the gas price cap"},
+		{name: "negative max fee", baseFee: big.NewInt(10), opts: TxOptions{MaxFee: big.NewInt(-1)}, err: "max fee -1 is negative"},
+		{name: "negative priority fee", baseFee: big.NewInt(10), opts: TxOptions{MaxPriorityFee: big.NewInt(-1)}, err: "priority fee -1 is negative"},
+		{name: "negative cap", opts: TxOptions{GasPriceCap: big.NewInt(-1)}, err: "gas price cap -1 is negative"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &bind.TransactOpts{}
+			err := tt.opts.setFees(context.Background(), opts, feeBackend{baseFee: tt.baseFee})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("setFees = %v, want an error containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setFees: %v", err)
+			}
+			for _, fee := range []struct {
+				name string
+				got  *big.Int
+				want int64
+			}{{"GasPrice", opts.GasPrice, tt.gasPrice}, {"GasTipCap", opts.GasTipCap, tt.tipCap}, {"GasFeeCap", opts.GasFeeCap, tt.feeCap}} {
+				if (fee.want == 0) != (fee.got == nil) || fee.got != nil && fee.got.Int64() != fee.want {
+					t.Errorf("%s = %v, want %d", fee.name, fee.got, fee.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTxStatusDecodesMinedRevert(t *testing.T) {
+	t.Parallel()
+	chain := newTestChain(t)
+	ctx := context.Background()
+	chain.pauseMining()
+
+	// A permit that passes gas estimation, but has expired when it is mined.
+	latest, err := chain.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := new(big.Int).SetUint64(latest.Time + 60)
+	permit, err := chain.account.SignPermit(ctx, testChainID, chain.client, chain.token, chain.mmarket, big.NewInt(1000), deadline, "")
+	if err != nil {
+		t.Fatalf("SignPermit: %v", err)
+	}
+	// Skip a nonce so that the permit stays queued while the clock moves past its
+	// deadline, then fill the gap to get it mined.
+	nonces, err := NewNonceManager(filepath.Join(t.TempDir(), "nonces.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := nonces.Next(ctx, testChainID, chain.account.Public, chain.client); err != nil {
+		t.Fatal(err)
+	}
+	sent, err := chain.account.SubmitPermit(ctx, testChainID, chain.client, permit, TxOptions{Nonces: nonces, NoWait: true})
+	if err != nil {
+		t.Fatalf("SubmitPermit: %v", err)
+	}
+	if err := chain.backend.AdjustTime(time.Hour); err != nil {
+		t.Fatalf("AdjustTime: %v", err)
+	}
+	if _, err := chain.account.ApproveSpender(ctx, testChainID, chain.client, chain.token, chain.mmarket, big.NewInt(1), TxOptions{NoWait: true}); err != nil {
+		t.Fatalf("ApproveSpender: %v", err)
+	}
+	chain.backend.Commit()
+
+	result, err := TxStatus(ctx, chain.client, sent.Hash)
+	reverted := checkRevert(t, err, "permit expired")
+	if result != reverted || result.Hash != sent.Hash || result.BlockNumber == nil || result.GasUsed == 0 {
+		t.Errorf("TxStatus = %+v, want the mined receipt of %s", result, sent.Hash.Hex())
+	}
+	if got := chain.allowance(t); got.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("allowance = %s, want 1 from the approval alone", got)
+	}
+}
@@ -1,27 +1,27 @@
 package ryskcore
 
 type Transfer struct {
-	Asset     string `json:"asset"`
-	ChainID   int    `json:"chainId"`
-	Amount    string `json:"amount"`
-	IsDeposit bool   `json:"isDeposit"`
-	Nonce     string `json:"nonce"`
-	Signature string `json:"signature"`
+	Asset     string  `json:"asset"`
+	ChainID   int     `json:"chainId"`
+	Amount    Uint256 `json:"amount"`
+	IsDeposit bool    `json:"isDeposit"`
+	Nonce     string  `json:"nonce"`
+	Signature string  `json:"signature"`
 }
 
 type Quote struct {
-	AssetAddress string `json:"assetAddress"`
-	ChainID      int    `json:"chainId"`
-	Expiry       int64  `json:"expiry"`
-	IsPut        bool   `json:"isPut"`
-	IsTakerBuy   bool   `json:"isTakerBuy"`
-	Maker        string `json:"maker"`
-	Nonce        string `json:"nonce"`
-	Price        string `json:"price"`
-	Quantity     string `json:"quantity"`
-	Strike       string `json:"strike"`
-	Signature    string `json:"signature"`
-	ValidUntil   int64  `json:"validUntil"`
+	AssetAddress string  `json:"assetAddress"`
+	ChainID      int     `json:"chainId"`
+	Expiry       int64   `json:"expiry"`
+	IsPut        bool    `json:"isPut"`
+	IsTakerBuy   bool    `json:"isTakerBuy"`
+	Maker        string  `json:"maker"`
+	Nonce        string  `json:"nonce"`
+	Price        Uint256 `json:"price"`
+	Quantity     Uint256 `json:"quantity"`
+	Strike       Uint256 `json:"strike"`
+	Signature    string  `json:"signature"`
+	ValidUntil   int64   `json:"validUntil"`
 }
 
 type QuoteNotification struct {
@@ -30,4 +30,4 @@ type QuoteNotification struct {
 	ChainID   int    `json:"chainId"`
 	NewBest   string `json:"newBest"`
 	Yours     string `json:"yours"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,135 @@
+package ryskcore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// Uint256 is an unsigned 256 bit integer, such as a price, quantity or strike of a
+// quote or the amount of a transfer, in base units. It marshals to JSON as a decimal
+// string, the form the protocol and the EIP-712 encoding expect, and unmarshals from
+// decimal or 0x prefixed hex strings and JSON numbers. The zero value is 0.
+//
+// Uint256 values are immutable: arithmetic returns new values and Big returns a copy.
+type Uint256 struct {
+	b [32]byte // big endian
+}
+
+// NewUint256 returns n as a Uint256, or an error if n is negative or does not fit in
+// 256 bits.
+func NewUint256(n *big.Int) (Uint256, error) {
+	var u Uint256
+	if n == nil {
+		return u, nil
+	}
+	if n.Sign() < 0 {
+		return Uint256{}, fmt.Errorf("%s is negative", n)
+	}
+	if n.BitLen() > 256 {
+		return Uint256{}, fmt.Errorf("%s overflows uint256", n)
+	}
+	n.FillBytes(u.b[:])
+	return u, nil
+}
+
+// Uint256FromUint64 returns v as a Uint256.
+func Uint256FromUint64(v uint64) Uint256 {
+	var u Uint256
+	binary.BigEndian.PutUint64(u.b[24:], v)
+	return u
+}
+
+// ParseUint256 parses base units given in decimal or 0x prefixed hex.
+func ParseUint256(s string) (Uint256, error) {
+	var n math.HexOrDecimal256
+	if err := n.UnmarshalText([]byte(s)); err != nil {
+		return Uint256{}, fmt.Errorf("invalid uint256 %q", s)
+	}
+	return NewUint256((*big.Int)(&n))
+}
+
+// MustParseUint256 is like ParseUint256 but panics on invalid input. It is meant
+// for constants.
+func MustParseUint256(s string) Uint256 {
+	u, err := ParseUint256(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// ParseDecimalUint256 converts a decimal such as "1500.25" or "2.5e3" to base units
+// with the given number of decimals, exactly, as ParseUnits does.
+func ParseDecimalUint256(value string, decimals uint8) (Uint256, error) {
+	n, err := ParseUnits(value, decimals)
+	if err != nil {
+		return Uint256{}, err
+	}
+	u, err := NewUint256(n)
+	if err != nil {
+		return Uint256{}, fmt.Errorf("amount %q: %w", value, err)
+	}
+	return u, nil
+}
+
+// Big returns u as a new big.Int.
+func (u Uint256) Big() *big.Int {
+	return new(big.Int).SetBytes(u.b[:])
+}
+
+// IsZero reports whether u is 0.
+func (u Uint256) IsZero() bool {
+	return u.b == [32]byte{}
+}
+
+// Cmp compares u and v, returning -1, 0 or +1.
+func (u Uint256) Cmp(v Uint256) int {
+	return bytes.Compare(u.b[:], v.b[:])
+}
+
+// Add returns u + v, or an error if the sum overflows 256 bits.
+func (u Uint256) Add(v Uint256) (Uint256, error) {
+	return NewUint256(new(big.Int).Add(u.Big(), v.Big()))
+}
+
+// Sub returns u - v, or an error if v is greater than u.
+func (u Uint256) Sub(v Uint256) (Uint256, error) {
+	return NewUint256(new(big.Int).Sub(u.Big(), v.Big()))
+}
+
+// String returns u in decimal.
+func (u Uint256) String() string {
+	return u.Big().String()
+}
+
+// Format returns u as a decimal with the given number of decimals, as FormatUnits does.
+func (u Uint256) Format(decimals uint8) string {
+	return FormatUnits(u.Big(), decimals)
+}
+
+func (u Uint256) MarshalJSON() ([]byte, error) {
+	return strconv.AppendQuote(nil, u.String()), nil
+}
+
+func (u *Uint256) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return err
+		}
+	} else if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	v, err := ParseUint256(s)
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
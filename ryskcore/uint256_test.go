@@ -0,0 +1,93 @@
+package ryskcore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestParseUint256(t *testing.T) {
+	for in, want := range map[string]string{
+		"0":                   "0",
+		"1250000000000000000": "1250000000000000000",
+		"0x0de0b6b3a7640000":  "1000000000000000000",
+		"0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff": "115792089237316195423570985008687907853269984665640564039457584007913129639935",
+	} {
+		got, err := ParseUint256(in)
+		if err != nil || got.String() != want {
+			t.Errorf("ParseUint256(%q) = %s, %v, want %s", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"x", "1.5", "-1", "3e21", "0x1" + strings.Repeat("0", 64)} {
+		if got, err := ParseUint256(in); err == nil {
+			t.Errorf("ParseUint256(%q) = %s, want an error", in, got)
+		}
+	}
+}
+
+func TestParseDecimalUint256(t *testing.T) {
+	got, err := ParseDecimalUint256("1500.25", 6)
+	if err != nil || got.String() != "1500250000" || got.Format(6) != "1500.25" {
+		t.Errorf("ParseDecimalUint256(1500.25, 6) = %s, %v", got, err)
+	}
+	if _, err := ParseDecimalUint256("-1", 6); err == nil {
+		t.Error("ParseDecimalUint256(-1) succeeded")
+	}
+	if _, err := ParsePrice("1e60"); err == nil {
+		t.Error("ParsePrice(1e60) succeeded, want an overflow")
+	}
+}
+
+func TestUint256Arithmetic(t *testing.T) {
+	a, b := Uint256FromUint64(5), Uint256FromUint64(7)
+	if sum, err := a.Add(b); err != nil || sum.String() != "12" {
+		t.Errorf("5 + 7 = %s, %v", sum, err)
+	}
+	if diff, err := b.Sub(a); err != nil || diff.String() != "2" {
+		t.Errorf("7 - 5 = %s, %v", diff, err)
+	}
+	if _, err := a.Sub(b); err == nil {
+		t.Error("5 - 7 succeeded")
+	}
+	max := MustParseUint256("0x" + strings.Repeat("f", 64))
+	if _, err := max.Add(Uint256FromUint64(1)); err == nil {
+		t.Error("max + 1 succeeded")
+	}
+	if a.Cmp(b) >= 0 || b.Cmp(a) <= 0 || a.Cmp(Uint256FromUint64(5)) != 0 {
+		t.Error("Cmp is not consistent")
+	}
+	if !(Uint256{}).IsZero() || (Uint256{}).String() != "0" {
+		t.Error("zero value is not 0")
+	}
+	big := a.Big()
+	big.SetInt64(100)
+	if a.String() != "5" {
+		t.Errorf("modifying Big changed the value to %s", a)
+	}
+}
+
+func TestUint256JSON(t *testing.T) {
+	data, err := json.Marshal(testQuote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"price":"1250000000000000000"`, `"quantity":"1000000000000000000"`, `"strike":"3000000000000000000000"`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("json.Marshal(testQuote) = %s, want %s", data, field)
+		}
+	}
+
+	var q Quote
+	if err := json.Unmarshal([]byte(`{"price":"0x10","quantity":25,"strike":"3000"}`), &q); err != nil {
+		t.Fatal(err)
+	}
+	if q.Price.String() != "16" || q.Quantity.String() != "25" || q.Strike.String() != "3000" {
+		t.Errorf("json.Unmarshal = %+v", q)
+	}
+	for _, bad := range []string{`{"price":"1.5"}`, `{"price":-1}`, `{"price":true}`} {
+		if err := json.Unmarshal([]byte(bad), &q); err == nil {
+			t.Errorf("json.Unmarshal(%s) succeeded", bad)
+		}
+	}
+}
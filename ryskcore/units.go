@@ -0,0 +1,134 @@
+package ryskcore
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NativeDecimals is the number of decimals of the native currency of every supported chain.
+const NativeDecimals uint8 = 18
+
+// FormatUnits formats an amount in base units as a decimal string with decimals
+// fractional digits, trimming trailing zeros, e.g. FormatUnits(1500250000, 6) is "1500.25".
+func FormatUnits(amount *big.Int, decimals uint8) string {
+	if amount == nil {
+		return "0"
+	}
+	sign := ""
+	abs := new(big.Int).Abs(amount)
+	if amount.Sign() < 0 {
+		sign = "-"
+	}
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	whole, frac := new(big.Int).QuoRem(abs, unit, new(big.Int))
+	if frac.Sign() == 0 {
+		return sign + whole.String()
+	}
+	fracStr := frac.String()
+	fracStr = strings.Repeat("0", int(decimals)-len(fracStr)) + fracStr
+	return sign + whole.String() + "." + strings.TrimRight(fracStr, "0")
+}
+
+// maxExponent bounds the exponent of amounts in scientific notation.
+const maxExponent = 256
+
+// ParseUnits parses a decimal string such as "1500.25" or "2.5e3" into base units with
+// the given number of decimals. The conversion is exact: it rejects inputs with more
+// fractional digits than decimals, e.g. "1.5e-7" with 6 decimals.
+func ParseUnits(value string, decimals uint8) (*big.Int, error) {
+	s := strings.TrimSpace(value)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(s[i+1:])
+		if err != nil || exp > maxExponent || exp < -maxExponent {
+			return nil, fmt.Errorf("invalid amount %q", value)
+		}
+		mantissa, exponent = s[:i], exp
+	}
+	whole, frac, _ := strings.Cut(mantissa, ".")
+	if whole == "" && frac == "" {
+		return nil, fmt.Errorf("invalid amount %q", value)
+	}
+	digits := whole + frac
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return nil, fmt.Errorf("invalid amount %q", value)
+		}
+	}
+
+	// digits is the amount in units of 10^-(len(frac)-exponent); shift it to base units.
+	shift := int(decimals) + exponent - len(frac)
+	if shift >= 0 {
+		digits += strings.Repeat("0", shift)
+	} else {
+		keep := max(len(digits)+shift, 0)
+		if strings.Trim(digits[keep:], "0") != "" {
+			return nil, fmt.Errorf("amount %q has more than %d decimals", value, decimals)
+		}
+		digits = digits[:keep]
+	}
+	amount, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		amount = new(big.Int)
+	}
+	if neg {
+		amount.Neg(amount)
+	}
+	return amount, nil
+}
+
+// Prices, quantities and strikes of quotes are fixed point numbers with these
+// decimals, whatever the decimals of the assets involved.
+const (
+	PriceDecimals    uint8 = 18
+	QuantityDecimals uint8 = 18
+	StrikeDecimals   uint8 = 18
+)
+
+// ParsePrice converts a decimal option price such as "12.5" to the base units of
+// Quote.Price.
+func ParsePrice(value string) (Uint256, error) {
+	return parseQuoteField("price", value, PriceDecimals)
+}
+
+// ParseQuantity converts a decimal option quantity such as "0.25" to the base units
+// of Quote.Quantity.
+func ParseQuantity(value string) (Uint256, error) {
+	return parseQuoteField("quantity", value, QuantityDecimals)
+}
+
+// ParseStrike converts a decimal strike such as "3500" to the base units of
+// Quote.Strike.
+func ParseStrike(value string) (Uint256, error) {
+	return parseQuoteField("strike", value, StrikeDecimals)
+}
+
+func parseQuoteField(name, value string, decimals uint8) (Uint256, error) {
+	amount, err := ParseDecimalUint256(value, decimals)
+	if err != nil {
+		return Uint256{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return amount, nil
+}
+
+// TokenDecimals reads the decimals of an ERC20 token.
+func TokenDecimals(ctx context.Context, caller bind.ContractCaller, token common.Address) (uint8, error) {
+	erc20, err := NewIERC20Caller(token, caller)
+	if err != nil {
+		return 0, err
+	}
+	decimals, err := erc20.Decimals(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read decimals of %s: %w", token.Hex(), err)
+	}
+	return decimals, nil
+}
@@ -0,0 +1,96 @@
+package ryskcore
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseUnits(t *testing.T) {
+	t.Parallel()
+	for _, tt := range []struct {
+		value    string
+		decimals uint8
+		want     string
+	}{
+		{"1500.25", 6, "1500250000"},
+		{"1500", 6, "1500000000"},
+		{"0.000001", 6, "1"},
+		{".5", 6, "500000"},
+		{"5.", 6, "5000000"},
+		{" 42 ", 0, "42"},
+		{"1", 18, "1000000000000000000"},
+		{"2.5e3", 6, "2500000000"},
+		{"2.5E3", 0, "2500"},
+		{"1e-6", 6, "1"},
+		{"1000e-3", 0, "1"},
+		{"1.50000", 2, "150"},
+		{"0", 18, "0"},
+		{"-1.5", 6, "-1500000"},
+		{"115792089237316195423570985008687907853269984665640564039457584007913129639935", 0, "115792089237316195423570985008687907853269984665640564039457584007913129639935"},
+	} {
+		got, err := ParseUnits(tt.value, tt.decimals)
+		if err != nil || got.String() != tt.want {
+			t.Errorf("ParseUnits(%q, %d) = %v, %v, want %s", tt.value, tt.decimals, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseUnitsErrors(t *testing.T) {
+	t.Parallel()
+	for _, tt := range []struct {
+		value    string
+		decimals uint8
+	}{
+		// Precision loss is rejected rather than rounded.
+		{"0.0000001", 6},
+		{"1.5", 0},
+		{"1.5e-7", 6},
+		{"15e-1", 0},
+		{"1500e-3", 0},
+		// Malformed input.
+		{"", 6},
+		{".", 6},
+		{"-", 6},
+		{"e3", 6},
+		{"1e", 6},
+		{"1e1.5", 6},
+		{"1e300", 6},
+		{"1.2.3", 6},
+		{"1,000", 6},
+		{"0x10", 0},
+		{"+1", 6},
+		{"--1", 6},
+		{"1 000", 6},
+		{"NaN", 6},
+	} {
+		if got, err := ParseUnits(tt.value, tt.decimals); err == nil {
+			t.Errorf("ParseUnits(%q, %d) = %s, want an error", tt.value, tt.decimals, got)
+		}
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	t.Parallel()
+	for _, tt := range []struct {
+		amount   *big.Int
+		decimals uint8
+		want     string
+	}{
+		{big.NewInt(1500250000), 6, "1500.25"},
+		{big.NewInt(1), 6, "0.000001"},
+		{big.NewInt(1000000), 6, "1"},
+		{big.NewInt(-1500000), 6, "-1.5"},
+		{big.NewInt(42), 0, "42"},
+		{nil, 6, "0"},
+	} {
+		if got := FormatUnits(tt.amount, tt.decimals); got != tt.want {
+			t.Errorf("FormatUnits(%v, %d) = %q, want %q", tt.amount, tt.decimals, got, tt.want)
+		}
+		if tt.amount == nil {
+			continue
+		}
+		if back, err := ParseUnits(FormatUnits(tt.amount, tt.decimals), tt.decimals); err != nil || back.Cmp(tt.amount) != 0 {
+			t.Errorf("ParseUnits(FormatUnits(%v)) = %v, %v", tt.amount, back, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package ryskcore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MaxQuoteValidity is how far in the future a quote's validUntil may be. Quotes
+// answer a live RFQ; a validUntil further out is most likely in milliseconds.
+const MaxQuoteValidity = 24 * time.Hour
+
+// Validate checks q for what the server would reject: malformed addresses, an empty
+// nonce, a zero price, quantity or strike, an unsupported chain, an expiry or
+// validUntil that is not in the future, a validUntil not before expiry or further
+// out than MaxQuoteValidity, and, if q is signed, a signature that is not from the
+// maker.
+// All problems found are reported together.
+func (q Quote) Validate(now time.Time) error {
+	var errs []error
+	if _, err := GetChain(q.ChainID); err != nil {
+		errs = append(errs, err)
+	}
+	errs = appendErr(errs, checkAddress("assetAddress", q.AssetAddress))
+	errs = appendErr(errs, checkAddress("maker", q.Maker))
+	if q.Nonce == "" {
+		errs = append(errs, errors.New("nonce is empty"))
+	}
+	errs = appendErr(errs, checkPositive("price", q.Price))
+	errs = appendErr(errs, checkPositive("quantity", q.Quantity))
+	errs = appendErr(errs, checkPositive("strike", q.Strike))
+
+	unix := now.Unix()
+	if q.Expiry <= unix {
+		errs = append(errs, fmt.Errorf("expiry %d is not in the future", q.Expiry))
+	}
+	switch {
+	case q.ValidUntil <= unix:
+		errs = append(errs, fmt.Errorf("validUntil %d is not in the future", q.ValidUntil))
+	case q.ValidUntil >= q.Expiry:
+		errs = append(errs, fmt.Errorf("validUntil %d is not before expiry %d", q.ValidUntil, q.Expiry))
+	case q.ValidUntil > now.Add(MaxQuoteValidity).Unix():
+		errs = append(errs, fmt.Errorf("validUntil %d is more than %s away", q.ValidUntil, MaxQuoteValidity))
+	}
+
+	if q.Signature != "" && len(errs) == 0 {
+		hash, err := QuoteHash(q)
+		if err == nil {
+			var signer common.Address
+			if signer, err = RecoverSigner(hash.Bytes(), q.Signature); err == nil && !strings.EqualFold(signer.Hex(), q.Maker) {
+				err = fmt.Errorf("signature is from %s, not maker %s", signer.Hex(), q.Maker)
+			}
+		}
+		errs = appendErr(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid quote: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func appendErr(errs []error, err error) []error {
+	if err != nil {
+		return append(errs, err)
+	}
+	return errs
+}
+
+func checkAddress(field, v string) error {
+	if !common.IsHexAddress(v) {
+		return fmt.Errorf("%s %q is not a hex address", field, v)
+	}
+	if common.HexToAddress(v) == ZeroAddress {
+		return fmt.Errorf("%s is the zero address", field)
+	}
+	return nil
+}
+
+func checkPositive(field string, v Uint256) error {
+	if v.IsZero() {
+		return fmt.Errorf("%s is zero", field)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package ryskcore
+
+import (
+	"crypto/ecdsa"
+	"strings"
+	"testing"
+	"time"
+
+	crypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestQuoteValidate(t *testing.T) {
+	now := time.Unix(testQuote.ValidUntil-60, 0)
+	if err := testQuote.Validate(now); err != nil {
+		t.Fatalf("Validate(testQuote) = %v", err)
+	}
+
+	for name, tc := range map[string]struct {
+		edit func(*Quote)
+		want string
+	}{
+		"unknown chain":        {func(q *Quote) { q.ChainID = 42 }, "42"},
+		"bad maker":            {func(q *Quote) { q.Maker = "maker" }, "maker \"maker\" is not a hex address"},
+		"zero asset":           {func(q *Quote) { q.AssetAddress = ZeroAddress.Hex() }, "assetAddress is the zero address"},
+		"zero quantity":        {func(q *Quote) { q.Quantity = Uint256{} }, "quantity is zero"},
+		"empty nonce":          {func(q *Quote) { q.Nonce = "" }, "nonce is empty"},
+		"expired":              {func(q *Quote) { q.Expiry = now.Unix() }, "expiry"},
+		"valid until past":     {func(q *Quote) { q.ValidUntil = now.Unix() - 1 }, "validUntil"},
+		"valid until expiry":   {func(q *Quote) { q.ValidUntil = q.Expiry }, "not before expiry"},
+		"valid until in ms":    {func(q *Quote) { q.ValidUntil, q.Expiry = now.UnixMilli(), now.UnixMilli()+1 }, "more than 24h0m0s away"},
+		"signature from other": {func(q *Quote) { q.Signature = signQuote(t, newTestKey(t), *q) }, "not maker"},
+	} {
+		q := testQuote
+		tc.edit(&q)
+		err := q.Validate(now)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: Validate = %v, want an error containing %q", name, err, tc.want)
+		}
+	}
+}
+
+func TestQuoteValidateSignedByMaker(t *testing.T) {
+	key := newTestKey(t)
+	q := testQuote
+	q.Maker = crypto.PubkeyToAddress(key.PublicKey).Hex()
+	q.Signature = signQuote(t, key, q)
+	if err := q.Validate(time.Unix(q.ValidUntil-60, 0)); err != nil {
+		t.Errorf("Validate = %v", err)
+	}
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func signQuote(t *testing.T, key *ecdsa.PrivateKey, q Quote) string {
+	t.Helper()
+	hash, err := QuoteHash(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := Account{Public: crypto.PubkeyToAddress(key.PublicKey), Private: key}
+	sig, err := account.Sign(hash.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
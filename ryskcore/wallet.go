@@ -0,0 +1,72 @@
+package ryskcore
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// WalletBackend is what InspectWallets needs from a node: contract calls and native balances.
+type WalletBackend interface {
+	bind.ContractCaller
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+}
+
+// Wallet is the on-chain state of an account relevant to the protocol.
+// Amounts are in base units; StrikeDecimals gives the scale of the strike asset amounts.
+type Wallet struct {
+	Account             common.Address `json:"account"`
+	ChainID             int            `json:"chainId"`
+	StrikeAsset         common.Address `json:"strikeAsset"`
+	StrikeDecimals      uint8          `json:"strikeDecimals"`
+	Native              *big.Int       `json:"native"`
+	StrikeBalance       *big.Int       `json:"strikeBalance"`
+	MMarketAllowance    *big.Int       `json:"mmarketAllowance"`
+	MarginPoolAllowance *big.Int       `json:"marginPoolAllowance"`
+}
+
+// InspectWallets reads the native balance, strike asset balance and strike asset
+// allowances to MMarket and MarginPool of each account on chainID.
+func InspectWallets(ctx context.Context, backend WalletBackend, chainID int, accounts []common.Address) ([]Wallet, error) {
+	addresses, err := GetAddresses(chainID)
+	if err != nil {
+		return nil, err
+	}
+	erc20, err := NewIERC20Caller(addresses.StrikeAsset, backend)
+	if err != nil {
+		return nil, err
+	}
+	opts := &bind.CallOpts{Context: ctx}
+
+	decimals, err := erc20.Decimals(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read strike asset decimals: %w", err)
+	}
+
+	wallets := make([]Wallet, 0, len(accounts))
+	for _, account := range accounts {
+		w := Wallet{
+			Account:        account,
+			ChainID:        chainID,
+			StrikeAsset:    addresses.StrikeAsset,
+			StrikeDecimals: decimals,
+		}
+		if w.Native, err = backend.BalanceAt(ctx, account, nil); err != nil {
+			return nil, fmt.Errorf("failed to read native balance of %s: %w", account.Hex(), err)
+		}
+		if w.StrikeBalance, err = erc20.BalanceOf(opts, account); err != nil {
+			return nil, fmt.Errorf("failed to read strike asset balance of %s: %w", account.Hex(), err)
+		}
+		if w.MMarketAllowance, err = erc20.Allowance(opts, account, addresses.MMarket); err != nil {
+			return nil, fmt.Errorf("failed to read MMarket allowance of %s: %w", account.Hex(), err)
+		}
+		if w.MarginPoolAllowance, err = erc20.Allowance(opts, account, addresses.MarginPool); err != nil {
+			return nil, fmt.Errorf("failed to read MarginPool allowance of %s: %w", account.Hex(), err)
+		}
+		wallets = append(wallets, w)
+	}
+	return wallets, nil
+}
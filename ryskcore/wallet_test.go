@@ -0,0 +1,69 @@
+package ryskcore
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TestInspectWallets registers the test chain in CHAINS, so it does not run in
+// parallel.
+func TestInspectWallets(t *testing.T) {
+	chain := newTestChain(t)
+	ctx := context.Background()
+	marginPool := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+
+	saved, registered := CHAINS[testChainID]
+	CHAINS[testChainID] = Chain{
+		ChainID:   testChainID,
+		Name:      "simulated",
+		Addresses: Addresses{MarginPool: marginPool, MMarket: chain.mmarket, StrikeAsset: chain.token},
+	}
+	t.Cleanup(func() {
+		if registered {
+			CHAINS[testChainID] = saved
+		} else {
+			delete(CHAINS, testChainID)
+		}
+	})
+
+	if _, err := chain.account.ApproveSpender(ctx, testChainID, chain.client, chain.token, chain.mmarket, big.NewInt(1000), TxOptions{}); err != nil {
+		t.Fatalf("ApproveSpender: %v", err)
+	}
+
+	empty := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	wallets, err := InspectWallets(ctx, chain.client, testChainID, []common.Address{chain.account.Public, empty})
+	if err != nil {
+		t.Fatalf("InspectWallets: %v", err)
+	}
+	if len(wallets) != 2 {
+		t.Fatalf("InspectWallets returned %d wallets, want 2", len(wallets))
+	}
+
+	w := wallets[0]
+	if w.Account != chain.account.Public || w.ChainID != testChainID || w.StrikeAsset != chain.token || w.StrikeDecimals != 6 {
+		t.Errorf("wallet = %+v, want the account's wallet with a 6 decimal strike asset", w)
+	}
+	if w.Native.Sign() <= 0 {
+		t.Errorf("Native = %s, want a balance", w.Native)
+	}
+	if w.StrikeBalance.Cmp(testTokenBalance) != 0 {
+		t.Errorf("StrikeBalance = %s, want %s", w.StrikeBalance, testTokenBalance)
+	}
+	if w.MMarketAllowance.Cmp(big.NewInt(1000)) != 0 || w.MarginPoolAllowance.Sign() != 0 {
+		t.Errorf("allowances = %s to MMarket, %s to MarginPool, want 1000 and 0", w.MMarketAllowance, w.MarginPoolAllowance)
+	}
+
+	if w := wallets[1]; w.Account != empty || w.StrikeBalance.Sign() != 0 || w.MMarketAllowance.Sign() != 0 {
+		t.Errorf("wallet of an unused account = %+v, want zero balances", w)
+	}
+}
+
+func TestInspectWalletsUnknownChain(t *testing.T) {
+	t.Parallel()
+	if _, err := InspectWallets(context.Background(), nil, -1, nil); err == nil {
+		t.Error("InspectWallets on an unregistered chain succeeded")
+	}
+}
@@ -0,0 +1,346 @@
+package ryskcore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// DefaultWatchBatchSize is the number of blocks fetched per log query.
+	DefaultWatchBatchSize uint64 = 2000
+	// DefaultWatchPollInterval is how often the watcher checks for new blocks.
+	DefaultWatchPollInterval = 4 * time.Second
+	// reorgWindow is how many blocks below the next block to scan the watcher
+	// remembers, and so how deep a reorg it can detect.
+	reorgWindow uint64 = 256
+)
+
+// WatchBackend is what WatchEvents needs from a node: log queries and headers.
+type WatchBackend interface {
+	bind.ContractFilterer
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// WatchConfig selects the events WatchEvents reports.
+type WatchConfig struct {
+	// Tokens are the ERC20 contracts whose events are watched.
+	Tokens []common.Address
+	// Parties are the accounts and contracts of interest. An event is reported when any
+	// of its from, to, owner or spender is a party, or always if Parties is empty.
+	Parties []common.Address
+	// FromBlock is the first block to scan. Nil starts at the latest confirmed block.
+	FromBlock *big.Int
+	// ToBlock is the last block to scan, after which WatchEvents returns. Nil follows
+	// the chain until the context is done.
+	ToBlock *big.Int
+	// Confirmations is how many blocks must be built on top of a block before its
+	// events are reported.
+	Confirmations uint64
+	// BatchSize is the number of blocks fetched per log query. Defaults to
+	// DefaultWatchBatchSize.
+	BatchSize uint64
+	// PollInterval is how often to check for new blocks. Defaults to
+	// DefaultWatchPollInterval.
+	PollInterval time.Duration
+}
+
+// Event names of ChainEvent.
+const (
+	EventTransfer = "Transfer"
+	EventApproval = "Approval"
+)
+
+// ChainEvent is an ERC20 Transfer or Approval event. From and To are set for
+// transfers, Owner and Spender for approvals. Removed is set when an event that
+// was already reported is undone by a reorg.
+type ChainEvent struct {
+	Event       string          `json:"event"`
+	Token       common.Address  `json:"token"`
+	From        *common.Address `json:"from,omitempty"`
+	To          *common.Address `json:"to,omitempty"`
+	Owner       *common.Address `json:"owner,omitempty"`
+	Spender     *common.Address `json:"spender,omitempty"`
+	Value       *big.Int        `json:"value"`
+	BlockNumber uint64          `json:"blockNumber"`
+	BlockHash   common.Hash     `json:"blockHash"`
+	TxHash      common.Hash     `json:"txHash"`
+	LogIndex    uint            `json:"logIndex"`
+	Removed     bool            `json:"removed,omitempty"`
+}
+
+// watcher is the state of WatchEvents: the next block to scan and, for the blocks
+// scanned within the reorg window, their hashes and reported events. Hashes are
+// remembered for the last block of each scan and for blocks with events.
+type watcher struct {
+	backend WatchBackend
+	cfg     WatchConfig
+	tokens  []*IERC20Filterer
+	sink    func(ChainEvent) error
+	next    uint64
+	hashes  map[uint64]common.Hash
+	events  map[uint64][]ChainEvent
+}
+
+// WatchEvents reports the Transfer and Approval events selected by cfg to sink, in
+// chain order, once they have cfg.Confirmations confirmations. When a reorg replaces
+// blocks whose events were reported, those events are reported again with Removed
+// set, and the new blocks are scanned. WatchEvents returns when cfg.ToBlock has been
+// scanned, the context is done, or sink returns an error.
+func WatchEvents(ctx context.Context, backend WatchBackend, cfg WatchConfig, sink func(ChainEvent) error) error {
+	w, err := newWatcher(ctx, backend, cfg, sink)
+	if err != nil {
+		return err
+	}
+	for {
+		done, err := w.poll(ctx)
+		if err != nil || done {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.cfg.PollInterval):
+		}
+	}
+}
+
+func newWatcher(ctx context.Context, backend WatchBackend, cfg WatchConfig, sink func(ChainEvent) error) (*watcher, error) {
+	if len(cfg.Tokens) == 0 {
+		return nil, fmt.Errorf("no tokens to watch")
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = DefaultWatchBatchSize
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultWatchPollInterval
+	}
+	w := &watcher{
+		backend: backend,
+		cfg:     cfg,
+		sink:    sink,
+		hashes:  make(map[uint64]common.Hash),
+		events:  make(map[uint64][]ChainEvent),
+	}
+	for _, token := range cfg.Tokens {
+		filterer, err := NewIERC20Filterer(token, backend)
+		if err != nil {
+			return nil, err
+		}
+		w.tokens = append(w.tokens, filterer)
+	}
+
+	if cfg.FromBlock != nil {
+		w.next = cfg.FromBlock.Uint64()
+	} else {
+		head, err := backend.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get head block: %w", err)
+		}
+		w.next = confirmedBlock(head.Number.Uint64(), cfg.Confirmations)
+	}
+	return w, nil
+}
+
+// poll handles reorgs and scans the confirmed blocks not scanned yet. It reports
+// whether cfg.ToBlock has been scanned.
+func (w *watcher) poll(ctx context.Context) (bool, error) {
+	if err := w.checkReorg(ctx); err != nil {
+		return false, err
+	}
+	head, err := w.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to get head block: %w", err)
+	}
+	last := confirmedBlock(head.Number.Uint64(), w.cfg.Confirmations)
+	if w.cfg.ToBlock != nil && w.cfg.ToBlock.Uint64() < last {
+		last = w.cfg.ToBlock.Uint64()
+	}
+
+	for w.next <= last {
+		end := min(w.next+w.cfg.BatchSize-1, last)
+		if err := w.scan(ctx, w.next, end); err != nil {
+			return false, err
+		}
+		w.next = end + 1
+	}
+	w.prune()
+	return w.cfg.ToBlock != nil && w.next > w.cfg.ToBlock.Uint64(), nil
+}
+
+// scan reports the events of blocks start to end and remembers their hashes.
+func (w *watcher) scan(ctx context.Context, start, end uint64) error {
+	header, err := w.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(end))
+	if err != nil {
+		return fmt.Errorf("failed to get block %d: %w", end, err)
+	}
+	events, err := w.fetch(ctx, start, end)
+	if err != nil {
+		return err
+	}
+	w.hashes[end] = header.Hash()
+	for _, e := range events {
+		w.hashes[e.BlockNumber] = e.BlockHash
+		w.events[e.BlockNumber] = append(w.events[e.BlockNumber], e)
+		if err := w.sink(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkReorg compares the remembered block hashes with the chain, from the highest
+// block down to the first one that is unchanged. The events of every block that was
+// replaced, or is gone because the chain got shorter, are reported as removed in
+// reverse order. Blocks in between are not remembered and may have changed too, so
+// scanning resumes right after the highest unchanged block. If no remembered block
+// is unchanged, the reorg is deeper than the window and scanning resumes from the
+// lowest replaced block.
+func (w *watcher) checkReorg(ctx context.Context) error {
+	numbers := make([]uint64, 0, len(w.hashes))
+	for n := range w.hashes {
+		numbers = append(numbers, n)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] > numbers[j] })
+
+	for _, n := range numbers {
+		header, err := w.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return fmt.Errorf("failed to get block %d: %w", n, err)
+		}
+		if err == nil && header.Hash() == w.hashes[n] {
+			w.next = min(w.next, n+1)
+			return nil
+		}
+		events := w.events[n]
+		for i := len(events) - 1; i >= 0; i-- {
+			removed := events[i]
+			removed.Removed = true
+			if err := w.sink(removed); err != nil {
+				return err
+			}
+		}
+		delete(w.hashes, n)
+		delete(w.events, n)
+		w.next = min(w.next, n)
+	}
+	return nil
+}
+
+// prune forgets blocks below the reorg window.
+func (w *watcher) prune() {
+	if w.next < reorgWindow {
+		return
+	}
+	for n := range w.hashes {
+		if n < w.next-reorgWindow {
+			delete(w.hashes, n)
+			delete(w.events, n)
+		}
+	}
+}
+
+// fetch returns the selected events of blocks start to end in chain order.
+// Events matched by several queries are returned once.
+func (w *watcher) fetch(ctx context.Context, start, end uint64) ([]ChainEvent, error) {
+	opts := &bind.FilterOpts{Start: start, End: &end, Context: ctx}
+	type key struct {
+		tx    common.Hash
+		index uint
+	}
+	seen := make(map[key]bool)
+	var events []ChainEvent
+	add := func(e ChainEvent) {
+		k := key{e.TxHash, e.LogIndex}
+		if !seen[k] {
+			seen[k] = true
+			events = append(events, e)
+		}
+	}
+
+	// Topics of one query are ANDed, so matching a party on either side takes two
+	// queries per event. Without parties, one unfiltered query is enough.
+	queries := [][2][]common.Address{{w.cfg.Parties, nil}, {nil, w.cfg.Parties}}
+	if len(w.cfg.Parties) == 0 {
+		queries = queries[:1]
+	}
+	for i, filterer := range w.tokens {
+		token := w.cfg.Tokens[i]
+		for _, q := range queries {
+			if err := fetchTransfers(opts, filterer, token, q[0], q[1], add); err != nil {
+				return nil, err
+			}
+			if err := fetchApprovals(opts, filterer, token, q[0], q[1], add); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].BlockNumber != events[j].BlockNumber {
+			return events[i].BlockNumber < events[j].BlockNumber
+		}
+		return events[i].LogIndex < events[j].LogIndex
+	})
+	return events, nil
+}
+
+func fetchTransfers(opts *bind.FilterOpts, filterer *IERC20Filterer, token common.Address, from, to []common.Address, add func(ChainEvent)) error {
+	transfers, err := filterer.FilterTransfer(opts, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to filter transfers of %s: %w", token.Hex(), err)
+	}
+	defer transfers.Close()
+	for transfers.Next() {
+		t := transfers.Event
+		add(newChainEvent(EventTransfer, token, t.Value, t.Raw, func(e *ChainEvent) {
+			e.From, e.To = &t.From, &t.To
+		}))
+	}
+	return transfers.Error()
+}
+
+func fetchApprovals(opts *bind.FilterOpts, filterer *IERC20Filterer, token common.Address, owner, spender []common.Address, add func(ChainEvent)) error {
+	approvals, err := filterer.FilterApproval(opts, owner, spender)
+	if err != nil {
+		return fmt.Errorf("failed to filter approvals of %s: %w", token.Hex(), err)
+	}
+	defer approvals.Close()
+	for approvals.Next() {
+		a := approvals.Event
+		add(newChainEvent(EventApproval, token, a.Value, a.Raw, func(e *ChainEvent) {
+			e.Owner, e.Spender = &a.Owner, &a.Spender
+		}))
+	}
+	return approvals.Error()
+}
+
+func newChainEvent(name string, token common.Address, value *big.Int, raw types.Log, parties func(*ChainEvent)) ChainEvent {
+	e := ChainEvent{
+		Event:       name,
+		Token:       token,
+		Value:       value,
+		BlockNumber: raw.BlockNumber,
+		BlockHash:   raw.BlockHash,
+		TxHash:      raw.TxHash,
+		LogIndex:    raw.Index,
+	}
+	parties(&e)
+	return e
+}
+
+// confirmedBlock is the highest block with the given number of confirmations.
+func confirmedBlock(head, confirmations uint64) uint64 {
+	if confirmations > head {
+		return 0
+	}
+	return head - confirmations
+}
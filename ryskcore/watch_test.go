@@ -0,0 +1,144 @@
+package ryskcore
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// approveAndMine approves amount to the mock MMarket and mines it into a block of
+// its own, followed by empty blocks. It returns the number of the approval's block.
+func (c *testChain) approveAndMine(t *testing.T, amount int64, empty int) uint64 {
+	t.Helper()
+	ctx := context.Background()
+	result, err := c.account.ApproveSpender(ctx, testChainID, c.client, c.token, c.mmarket, big.NewInt(amount), TxOptions{NoWait: true})
+	if err != nil {
+		t.Fatalf("ApproveSpender: %v", err)
+	}
+	c.backend.Commit()
+	receipt, err := c.client.TransactionReceipt(ctx, result.Hash)
+	if err != nil {
+		t.Fatalf("TransactionReceipt: %v", err)
+	}
+	for range empty {
+		c.backend.Commit()
+	}
+	return receipt.BlockNumber.Uint64()
+}
+
+func (c *testChain) header(t *testing.T, number uint64) *types.Header {
+	t.Helper()
+	header, err := c.client.HeaderByNumber(context.Background(), new(big.Int).SetUint64(number))
+	if err != nil {
+		t.Fatalf("HeaderByNumber(%d): %v", number, err)
+	}
+	return header
+}
+
+// newTestWatcher watches the mock ERC20 from block from, collecting its events.
+func newTestWatcher(t *testing.T, c *testChain, from uint64) (*watcher, *[]ChainEvent) {
+	t.Helper()
+	events := new([]ChainEvent)
+	w, err := newWatcher(context.Background(), c.client, WatchConfig{
+		Tokens:    []common.Address{c.token},
+		FromBlock: new(big.Int).SetUint64(from),
+	}, func(e ChainEvent) error {
+		*events = append(*events, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("newWatcher: %v", err)
+	}
+	return w, events
+}
+
+func poll(t *testing.T, w *watcher) {
+	t.Helper()
+	if _, err := w.poll(context.Background()); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+}
+
+func checkApproval(t *testing.T, e ChainEvent, value int64, block uint64, removed bool) {
+	t.Helper()
+	if e.Event != EventApproval || e.Value.Cmp(big.NewInt(value)) != 0 || e.BlockNumber != block || e.Removed != removed {
+		t.Errorf("event = %+v, want an approval of %d in block %d with removed %t", e, value, block, removed)
+	}
+}
+
+// TestWatchRescansAfterUnchangedBlock reorgs blocks the watcher scanned without
+// remembering their hashes. The new events there must not be skipped.
+func TestWatchRescansAfterUnchangedBlock(t *testing.T) {
+	t.Parallel()
+	chain := newTestChain(t)
+	chain.pauseMining()
+	first := chain.approveAndMine(t, 1000, 3)
+
+	w, events := newTestWatcher(t, chain, first)
+	poll(t, w)
+	if len(*events) != 1 {
+		t.Fatalf("events = %+v, want the first approval", *events)
+	}
+	checkApproval(t, (*events)[0], 1000, first, false)
+
+	// Replace the empty blocks after first+1 with a longer branch holding a new approval.
+	if err := chain.backend.Fork(chain.header(t, first+1).Hash()); err != nil {
+		t.Fatalf("Fork: %v", err)
+	}
+	second := chain.approveAndMine(t, 2000, 2)
+	if second != first+2 {
+		t.Fatalf("second approval mined in block %d, want %d", second, first+2)
+	}
+
+	poll(t, w)
+	if len(*events) != 2 {
+		t.Fatalf("events = %+v, want the approvals of both branches", *events)
+	}
+	checkApproval(t, (*events)[1], 2000, second, false)
+	if got, want := (*events)[1].BlockHash, chain.header(t, second).Hash(); got != want {
+		t.Errorf("second approval block hash = %s, want %s", got.Hex(), want.Hex())
+	}
+}
+
+// TestWatchReportsRemovedEvents reorgs the block of a reported event, first to a
+// shorter branch and then to a longer one.
+func TestWatchReportsRemovedEvents(t *testing.T) {
+	t.Parallel()
+	chain := newTestChain(t)
+	chain.pauseMining()
+	first := chain.approveAndMine(t, 1000, 1)
+
+	w, events := newTestWatcher(t, chain, first)
+	poll(t, w)
+	if len(*events) != 1 {
+		t.Fatalf("events = %+v, want the approval", *events)
+	}
+	reported := (*events)[0]
+
+	// Right after the fork the chain is shorter than the remembered blocks.
+	if err := chain.backend.Fork(chain.header(t, first-1).Hash()); err != nil {
+		t.Fatalf("Fork: %v", err)
+	}
+	poll(t, w)
+	if len(*events) != 2 {
+		t.Fatalf("events = %+v, want the approval removed", *events)
+	}
+	checkApproval(t, (*events)[1], 1000, first, true)
+	if (*events)[1].BlockHash != reported.BlockHash || (*events)[1].TxHash != reported.TxHash {
+		t.Errorf("removed event = %+v, want %+v", (*events)[1], reported)
+	}
+
+	// The new branch may include the approval again, but never removes anything.
+	for range 3 {
+		chain.backend.Commit()
+	}
+	poll(t, w)
+	for _, e := range (*events)[2:] {
+		if e.Removed || e.BlockHash == reported.BlockHash {
+			t.Errorf("event = %+v after the reorg, want one of the new branch", e)
+		}
+	}
+}